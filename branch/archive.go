@@ -0,0 +1,172 @@
+package branch
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/darklang/dark-multi/config"
+	"github.com/darklang/dark-multi/container"
+	"github.com/darklang/dark-multi/tmux"
+)
+
+// archiveTimeFormat is the timestamp suffix on archive directories.
+const archiveTimeFormat = "20060102T150405"
+
+// ArchivedBranch is a branch moved aside by Archive.
+// Its directory holds repo/ (the clone) and overrides/ (metadata, env).
+type ArchivedBranch struct {
+	Name       string
+	Dir        string
+	ArchivedAt time.Time
+}
+
+// ArchiveDir returns where archived branches are kept.
+func ArchiveDir() string {
+	return filepath.Join(config.ConfigDir, "archive")
+}
+
+// Archive stops a branch and removes its container, but moves its files
+// into the archive instead of deleting them, so Restore can bring it back.
+func Archive(b *Branch) (*ArchivedBranch, error) {
+	if err := checkSafePath(b.Name); err != nil {
+		return nil, err
+	}
+	if !b.Exists() {
+		return nil, fmt.Errorf("branch %s does not exist", b.Name)
+	}
+
+	now := time.Now()
+	a := &ArchivedBranch{
+		Name:       b.Name,
+		Dir:        filepath.Join(ArchiveDir(), b.Name+"-"+now.Format(archiveTimeFormat)),
+		ArchivedAt: now,
+	}
+	if err := os.MkdirAll(a.Dir, 0755); err != nil {
+		return nil, err
+	}
+	// The clone is moved with a rename, which fails across filesystems; find
+	// out before the container is stopped and removed
+	if err := checkRenameable(filepath.Dir(b.Path), a.Dir); err != nil {
+		os.Remove(a.Dir)
+		return nil, fmt.Errorf("can't move %s into %s: %w", b.Path, ArchiveDir(), err)
+	}
+
+	Stop(b)
+	tmux.KillBranchSessions(b.Name)
+	container.RemoveContainersByLabel(fmt.Sprintf("dark-dev-container=%s", b.Name))
+
+	if err := os.Rename(b.Path, filepath.Join(a.Dir, "repo")); err != nil {
+		os.Remove(a.Dir)
+		return nil, fmt.Errorf("failed to move branch into archive: %w", err)
+	}
+	if _, err := os.Stat(b.OverrideDir); err == nil {
+		if err := os.Rename(b.OverrideDir, filepath.Join(a.Dir, "overrides")); err != nil {
+			return a, fmt.Errorf("archived files, but failed to move override dir: %w", err)
+		}
+	}
+	return a, nil
+}
+
+// checkRenameable renames a scratch directory from one directory into
+// another, reporting whether a real rename between them would work.
+func checkRenameable(fromDir, toDir string) error {
+	probe, err := os.MkdirTemp(fromDir, ".multi-archive-probe-")
+	if err != nil {
+		return err
+	}
+	dest := filepath.Join(toDir, filepath.Base(probe))
+	if err := os.Rename(probe, dest); err != nil {
+		os.Remove(probe)
+		if le, ok := err.(*os.LinkError); ok {
+			return le.Err // the probe paths mean nothing to the caller
+		}
+		return err
+	}
+	return os.Remove(dest)
+}
+
+// ListArchived returns archived branches, newest first.
+func ListArchived() []*ArchivedBranch {
+	var archived []*ArchivedBranch
+	entries, err := os.ReadDir(ArchiveDir())
+	if err != nil {
+		return archived
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		idx := strings.LastIndex(entry.Name(), "-")
+		if idx <= 0 {
+			continue
+		}
+		at, err := time.ParseInLocation(archiveTimeFormat, entry.Name()[idx+1:], time.Local)
+		if err != nil {
+			continue
+		}
+		archived = append(archived, &ArchivedBranch{
+			Name:       entry.Name()[:idx],
+			Dir:        filepath.Join(ArchiveDir(), entry.Name()),
+			ArchivedAt: at,
+		})
+	}
+
+	sort.Slice(archived, func(i, j int) bool {
+		return archived[i].ArchivedAt.After(archived[j].ArchivedAt)
+	})
+	return archived
+}
+
+// Restore moves the most recent archive of name back into place.
+// The branch keeps its instance ID unless another branch has taken it.
+func Restore(name string) (*Branch, error) {
+	var a *ArchivedBranch
+	for _, candidate := range ListArchived() {
+		if candidate.Name == name {
+			a = candidate
+			break
+		}
+	}
+	if a == nil {
+		return nil, fmt.Errorf("no archived branch named %s", name)
+	}
+
+	b := New(name)
+	if b.Exists() || b.IsManaged() {
+		return nil, fmt.Errorf("branch %s already exists", name)
+	}
+
+	if err := os.MkdirAll(config.DarkRoot, 0755); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(filepath.Join(a.Dir, "repo"), b.Path); err != nil {
+		return nil, fmt.Errorf("failed to restore files: %w", err)
+	}
+
+	overrides := filepath.Join(a.Dir, "overrides")
+	if _, err := os.Stat(overrides); err == nil {
+		os.MkdirAll(config.OverridesDir, 0755)
+		if err := os.Rename(overrides, b.OverrideDir); err != nil {
+			return b, fmt.Errorf("restored files, but failed to restore override dir: %w", err)
+		}
+	}
+	os.Remove(a.Dir)
+
+	if !b.IsManaged() {
+		return b, b.WriteMetadata(FindNextInstanceID())
+	}
+	// Another branch may have been given this ID while it was archived
+	id := b.InstanceID()
+	for _, other := range GetManagedBranches() {
+		if other.Name != name && other.InstanceID() == id {
+			b.SetMetadata("ID", fmt.Sprintf("%d", FindNextInstanceID()))
+			break
+		}
+	}
+	return b, nil
+}
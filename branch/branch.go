@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -13,6 +14,13 @@ import (
 	"github.com/darklang/dark-multi/config"
 )
 
+const (
+	// StaleAfter is how old a branch can get before it's flagged as stale.
+	StaleAfter = 14 * 24 * time.Hour
+	// StaleBehind is how far behind origin/main a branch can fall before it's flagged as stale.
+	StaleBehind = 200
+)
+
 // Branch represents a branch clone.
 type Branch struct {
 	Name         string
@@ -21,9 +29,41 @@ type Branch struct {
 	MetadataFile string
 }
 
+// ValidateName returns an error if name can't be used for a new branch. Names
+// must be safe as a directory and as one DNS label, since the proxy routes
+// <canvas>.<branch>.dlio.localhost - a '.' would split the branch in two.
+func ValidateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("branch name is empty")
+	}
+	for _, c := range name {
+		valid := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') ||
+			(c >= '0' && c <= '9') || c == '-' || c == '_'
+		if !valid {
+			return fmt.Errorf("invalid branch name %q: only letters, digits, '-' and '_' are allowed", name)
+		}
+	}
+	return nil
+}
+
+// checkSafePath returns an error if name would resolve outside its own
+// directory under DarkRoot. It's looser than ValidateName so branches made
+// before '.' was disallowed can still be removed and archived.
+func checkSafePath(name string) error {
+	if name == "" || strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid branch name %q", name)
+	}
+	return nil
+}
+
+// pathFor derives the clone path for a branch name without validation.
+func pathFor(name string) string {
+	return filepath.Join(config.DarkRoot, name)
+}
+
 // New creates a new Branch instance.
 func New(name string) *Branch {
-	path := filepath.Join(config.DarkRoot, name)
+	path := pathFor(name)
 	overrideDir := filepath.Join(config.OverridesDir, name)
 	return &Branch{
 		Name:         name,
@@ -40,6 +80,12 @@ func (b *Branch) Exists() bool {
 	return err == nil && info.IsDir()
 }
 
+// HasDevcontainer returns true if the branch has a .devcontainer/devcontainer.json.
+func (b *Branch) HasDevcontainer() bool {
+	_, err := os.Stat(filepath.Join(b.Path, ".devcontainer", "devcontainer.json"))
+	return err == nil
+}
+
 // IsManaged returns true if this is a dark-multi managed branch.
 func (b *Branch) IsManaged() bool {
 	// Check override dir exists and has metadata file
@@ -76,6 +122,69 @@ func (b *Branch) GetPath() string {
 	return b.Path
 }
 
+// ResourceLimits returns the container --cpus and --memory values
+// (implements container.BranchInfo). Metadata overrides set via
+// 'multi limits' win over the budget-derived defaults.
+func (b *Branch) ResourceLimits() (cpus, memory string) {
+	defCPUs, defMemGB := config.DefaultContainerLimits()
+	cpus = strconv.FormatFloat(defCPUs, 'f', -1, 64)
+	memory = fmt.Sprintf("%dg", defMemGB)
+
+	md := b.Metadata()
+	if v := md["CPUS"]; v != "" {
+		cpus = v
+	}
+	if v := md["MEMORY"]; v != "" {
+		memory = v
+	}
+	return cpus, memory
+}
+
+// envFile returns the path to the branch's extra container env vars.
+func (b *Branch) envFile() string {
+	return filepath.Join(b.OverrideDir, "env")
+}
+
+// Env returns extra container env vars for the branch (implements container.BranchInfo).
+func (b *Branch) Env() map[string]string {
+	env := make(map[string]string)
+	content, err := os.ReadFile(b.envFile())
+	if err != nil {
+		return env
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if idx := strings.Index(line, "="); idx > 0 {
+			env[line[:idx]] = line[idx+1:]
+		}
+	}
+	return env
+}
+
+// SetEnv sets an extra container env var. An empty value removes it.
+func (b *Branch) SetEnv(key, value string) error {
+	env := b.Env()
+	if value == "" {
+		delete(env, key)
+	} else {
+		env[key] = value
+	}
+
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var content strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&content, "%s=%s\n", k, env[k])
+	}
+	if err := os.MkdirAll(b.OverrideDir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(b.envFile(), []byte(content.String()), 0600)
+}
+
 // InstanceID returns the branch instance ID.
 func (b *Branch) InstanceID() int {
 	if id, ok := b.Metadata()["ID"]; ok {
@@ -117,6 +226,25 @@ func (b *Branch) IsRunning() bool {
 	return err == nil && id != ""
 }
 
+// RunningNames returns the names of branches with a running container, from a
+// single docker ps. Use it instead of IsRunning when checking many branches.
+func RunningNames() map[string]bool {
+	running := make(map[string]bool)
+	out, err := exec.Command("docker", "ps", "--format", `{{.Names}}	{{.Label "dark-dev-container"}}`).Output()
+	if err != nil {
+		return running
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		name, label, _ := strings.Cut(strings.TrimSpace(line), "\t")
+		if label != "" {
+			running[label] = true
+		} else if strings.HasPrefix(name, "dark-") {
+			running[strings.TrimPrefix(name, "dark-")] = true
+		}
+	}
+	return running
+}
+
 // HasChanges returns true if there are uncommitted changes.
 func (b *Branch) HasChanges() bool {
 	if !b.Exists() {
@@ -127,6 +255,15 @@ func (b *Branch) HasChanges() bool {
 	return err == nil && len(strings.TrimSpace(string(out))) > 0
 }
 
+// GitBranch returns the checked-out git branch, falling back to the branch name.
+func (b *Branch) GitBranch() string {
+	out, err := exec.Command("git", "-C", b.Path, "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil || strings.TrimSpace(string(out)) == "HEAD" {
+		return b.Name
+	}
+	return strings.TrimSpace(string(out))
+}
+
 // GitStatus returns modified and untracked file counts.
 func (b *Branch) GitStatus() (modified int, untracked int) {
 	if !b.Exists() {
@@ -152,45 +289,64 @@ func (b *Branch) GitStatus() (modified int, untracked int) {
 
 // GitStats returns commits ahead of origin/main and total lines added/removed (committed + uncommitted).
 func (b *Branch) GitStats() (commits int, added int, removed int) {
-	if !b.Exists() || b.Name == "main" {
-		return 0, 0, 0
-	}
+	stats := b.cachedGitStats()
+	return stats.Commits, stats.Added, stats.Removed
+}
 
-	// Count commits ahead of origin/main
-	cmd := exec.Command("git", "-C", b.Path, "rev-list", "--count", "origin/main..HEAD")
-	out, err := cmd.Output()
-	if err == nil {
-		fmt.Sscanf(strings.TrimSpace(string(out)), "%d", &commits)
-	}
+// Created returns when the branch was created (zero if unknown).
+func (b *Branch) Created() time.Time {
+	t, _ := time.Parse(time.RFC3339, b.Metadata()["CREATED"])
+	return t
+}
 
-	// Get total diff stats vs origin/main (includes uncommitted)
-	// Using "origin/main" without "..." shows diff including working tree
-	cmd = exec.Command("git", "-C", b.Path, "diff", "--numstat", "origin/main")
-	out, err = cmd.Output()
-	if err == nil {
-		for _, line := range strings.Split(string(out), "\n") {
-			fields := strings.Fields(line)
-			if len(fields) >= 2 {
-				var a, r int
-				fmt.Sscanf(fields[0], "%d", &a)
-				fmt.Sscanf(fields[1], "%d", &r)
-				added += a
-				removed += r
-			}
-		}
+// Behind returns how many commits origin/main has that HEAD doesn't.
+func (b *Branch) Behind() int {
+	if !b.Exists() {
+		return 0
+	}
+	out, err := exec.Command("git", "-C", b.Path, "rev-list", "--count", "HEAD..origin/main").Output()
+	if err != nil {
+		return 0
 	}
+	var behind int
+	fmt.Sscanf(strings.TrimSpace(string(out)), "%d", &behind)
+	return behind
+}
 
-	return commits, added, removed
+// Staleness returns a short description like "age: 12d, 340 behind" and
+// whether the branch is old or far enough behind to be a merge risk.
+func (b *Branch) Staleness() (string, bool) {
+	var parts []string
+	age := time.Duration(0)
+	if created := b.Created(); !created.IsZero() {
+		age = time.Since(created)
+		parts = append(parts, fmt.Sprintf("age: %dd", int(age.Hours()/24)))
+	}
+	behind := b.Behind()
+	if behind > 0 {
+		parts = append(parts, fmt.Sprintf("%d behind", behind))
+	}
+	return strings.Join(parts, ", "), age > StaleAfter || behind > StaleBehind
 }
 
 // PortBase returns the test port base for this branch.
 func (b *Branch) PortBase() int {
-	return 10011 + b.InstanceID()*100
+	return PortBaseFor(b.InstanceID())
 }
 
 // BwdPortBase returns the BwdServer port base for this branch.
 func (b *Branch) BwdPortBase() int {
-	return 11001 + b.InstanceID()*100
+	return BwdPortBaseFor(b.InstanceID())
+}
+
+// PortBaseFor returns the test port base for an instance ID.
+func PortBaseFor(instanceID int) int {
+	return 10011 + instanceID*100
+}
+
+// BwdPortBaseFor returns the BwdServer port base for an instance ID.
+func BwdPortBaseFor(instanceID int) int {
+	return 11001 + instanceID*100
 }
 
 // WriteMetadata writes the branch metadata file.
@@ -203,6 +359,27 @@ func (b *Branch) WriteMetadata(instanceID int) error {
 	return os.WriteFile(b.MetadataFile, []byte(content), 0644)
 }
 
+// SetMetadata sets a single metadata key, preserving the other entries.
+func (b *Branch) SetMetadata(key, value string) error {
+	content, err := os.ReadFile(b.MetadataFile)
+	if err != nil {
+		return err
+	}
+	var lines []string
+	found := false
+	for _, line := range strings.Split(strings.TrimRight(string(content), "\n"), "\n") {
+		if strings.HasPrefix(line, key+"=") {
+			line = key + "=" + value
+			found = true
+		}
+		lines = append(lines, line)
+	}
+	if !found {
+		lines = append(lines, key+"="+value)
+	}
+	return os.WriteFile(b.MetadataFile, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
+
 // StatusLine returns a formatted status line for display.
 func (b *Branch) StatusLine() string {
 	status := "stopped"
@@ -10,12 +10,23 @@ import (
 	"github.com/darklang/dark-multi/config"
 )
 
-// FindNextInstanceID finds the next available instance ID.
+// FindNextInstanceID returns the lowest instance ID not used by any branch,
+// so IDs (and ports) freed by removed branches are reused.
 func FindNextInstanceID() int {
-	maxID := 0
+	used := usedInstanceIDs()
+	id := 1
+	for used[id] {
+		id++
+	}
+	return id
+}
+
+// usedInstanceIDs returns the instance IDs recorded in branch metadata.
+func usedInstanceIDs() map[int]bool {
+	used := make(map[int]bool)
 	entries, err := os.ReadDir(config.OverridesDir)
 	if err != nil {
-		return 1
+		return used
 	}
 
 	for _, entry := range entries {
@@ -30,15 +41,13 @@ func FindNextInstanceID() int {
 		for _, line := range strings.Split(string(content), "\n") {
 			if strings.HasPrefix(line, "ID=") {
 				if id, err := strconv.Atoi(line[3:]); err == nil {
-					if id > maxID {
-						maxID = id
-					}
+					used[id] = true
 				}
 			}
 		}
 	}
 
-	return maxID + 1
+	return used
 }
 
 // FindSourceRepo finds a repo to clone from.
@@ -52,7 +61,7 @@ func FindSourceRepo() string {
 	}
 
 	// Check for 'main' branch (must be fully cloned - has devcontainer.json)
-	mainPath := filepath.Join(config.DarkRoot, "main")
+	mainPath := pathFor("main")
 	devcontainerPath := filepath.Join(mainPath, ".devcontainer", "devcontainer.json")
 	if _, err := os.Stat(devcontainerPath); err == nil {
 		return mainPath
@@ -0,0 +1,82 @@
+package branch
+
+import (
+	"io/fs"
+	"path/filepath"
+
+	"github.com/darklang/dark-multi/container"
+)
+
+// DiskSize is the disk a branch takes up: its clone plus its named volumes.
+type DiskSize struct {
+	Worktree int64
+	Volumes  int64
+}
+
+// Total returns the combined size in bytes.
+func (u DiskSize) Total() int64 {
+	return u.Worktree + u.Volumes
+}
+
+// DiskUsage sums the size of a branch's clone and its docker volumes. Walking
+// a clone can take a few seconds, so call it off the UI goroutine.
+func DiskUsage(b *Branch) (DiskSize, error) {
+	var usage DiskSize
+	filepath.WalkDir(b.Path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				usage.Worktree += info.Size()
+			}
+		}
+		return nil
+	})
+
+	sizes, err := container.VolumeSizes()
+	if err != nil {
+		return usage, err
+	}
+	for _, v := range container.BranchVolumes(b.Name) {
+		usage.Volumes += sizes[v]
+	}
+	return usage, nil
+}
+
+// OrphanedVolumes returns per-branch volumes that no existing or archived
+// branch could own. A volume whose name fits several branches is kept if any
+// of them is still around.
+func OrphanedVolumes() ([]string, error) {
+	vols, err := container.ListVolumes()
+	if err != nil {
+		return nil, err
+	}
+	archived := archivedNames()
+	var orphans []string
+	for _, v := range vols {
+		if len(container.VolumeBranches(v)) > 0 && !volumeOwned(v, "", archived) {
+			orphans = append(orphans, v)
+		}
+	}
+	return orphans, nil
+}
+
+// volumeOwned reports whether an existing or archived branch other than
+// except could own a per-branch volume.
+func volumeOwned(volume, except string, archived map[string]bool) bool {
+	for _, name := range container.VolumeBranches(volume) {
+		if name != except && (archived[name] || New(name).Exists()) {
+			return true
+		}
+	}
+	return false
+}
+
+func archivedNames() map[string]bool {
+	names := make(map[string]bool)
+	for _, a := range ListArchived() {
+		names[a.Name] = true
+	}
+	return names
+}
@@ -0,0 +1,41 @@
+package branch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/darklang/dark-multi/config"
+)
+
+func TestVolumeOwned(t *testing.T) {
+	root := t.TempDir()
+	saved := config.DarkRoot
+	config.DarkRoot = root
+	t.Cleanup(func() { config.DarkRoot = saved })
+
+	if err := os.MkdirAll(filepath.Join(root, "foo", ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	archived := map[string]bool{"old": true}
+
+	tests := []struct {
+		volume string
+		except string
+		want   bool
+	}{
+		{"dark_nuget_foo", "", true},
+		{"dark_nuget_foo", "foo", false},
+		{"dark_nuget_old", "", true},
+		{"dark_nuget_gone", "", false},
+		// Insiders volume of "foo", or extensions volume of "insiders-foo".
+		{"dark-vscode-ext-insiders-foo", "", true},
+		{"dark-vscode-ext-insiders-foo", "insiders-foo", true},
+		{"dark-vscode-ext-insiders-foo", "foo", false},
+	}
+	for _, tt := range tests {
+		if got := volumeOwned(tt.volume, tt.except, archived); got != tt.want {
+			t.Errorf("volumeOwned(%q, %q) = %v, want %v", tt.volume, tt.except, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package branch
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// gitStatsMaxAge bounds how long cached stats are trusted. Editing a file that
+// wasn't already dirty doesn't touch anything in .git, so the cache can't see it.
+const gitStatsMaxAge = 30 * time.Second
+
+// GitSummary is a branch's commits ahead of origin/main and lines changed vs it.
+type GitSummary struct {
+	Commits int
+	Added   int
+	Removed int
+}
+
+type gitStatsEntry struct {
+	key     string
+	stats   GitSummary
+	dirty   []string // files in the last diff, whose mtimes feed the key
+	checked time.Time
+}
+
+var gitStatsCache = struct {
+	sync.Mutex
+	entries map[string]gitStatsEntry // branch path -> entry
+}{entries: make(map[string]gitStatsEntry)}
+
+// GitStatsBatch returns git stats for each branch, keyed by name. Branches
+// whose HEAD, index and dirty files haven't changed are served from cache;
+// the rest are computed in parallel.
+func GitStatsBatch(branches []*Branch) map[string]GitSummary {
+	result := make(map[string]GitSummary, len(branches))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, b := range branches {
+		wg.Add(1)
+		go func(b *Branch) {
+			defer wg.Done()
+			stats := b.cachedGitStats()
+			mu.Lock()
+			result[b.Name] = stats
+			mu.Unlock()
+		}(b)
+	}
+	wg.Wait()
+	return result
+}
+
+// cachedGitStats returns the branch's git stats, recomputing only when the
+// repo has changed since the last call.
+func (b *Branch) cachedGitStats() GitSummary {
+	if !b.Exists() || b.Name == "main" {
+		return GitSummary{}
+	}
+
+	gitStatsCache.Lock()
+	entry, ok := gitStatsCache.entries[b.Path]
+	gitStatsCache.Unlock()
+
+	key := gitStatsKey(b.Path, entry.dirty)
+	if ok && entry.key == key && time.Since(entry.checked) < gitStatsMaxAge {
+		return entry.stats
+	}
+
+	stats, dirty := computeGitStats(b.Path)
+	// Key on the new dirty set so the next call compares like with like
+	entry = gitStatsEntry{key: gitStatsKey(b.Path, dirty), stats: stats, dirty: dirty, checked: time.Now()}
+	gitStatsCache.Lock()
+	gitStatsCache.entries[b.Path] = entry
+	gitStatsCache.Unlock()
+	return stats
+}
+
+// gitStatsKey fingerprints the files that change when HEAD moves, the index
+// changes, origin/main is fetched, or an already-dirty file is edited.
+func gitStatsKey(path string, dirty []string) string {
+	gitDir := filepath.Join(path, ".git")
+	files := []string{
+		filepath.Join(gitDir, "HEAD"),
+		filepath.Join(gitDir, "index"),
+		filepath.Join(gitDir, "logs", "HEAD"),
+		filepath.Join(gitDir, "FETCH_HEAD"),
+		filepath.Join(gitDir, "packed-refs"),
+	}
+	for _, f := range dirty {
+		files = append(files, filepath.Join(path, f))
+	}
+
+	var sb strings.Builder
+	for _, f := range files {
+		if info, err := os.Stat(f); err == nil {
+			fmt.Fprintf(&sb, "%d:%d;", info.ModTime().UnixNano(), info.Size())
+		} else {
+			sb.WriteString("-;")
+		}
+	}
+	return sb.String()
+}
+
+// computeGitStats runs git to count commits ahead of origin/main and lines
+// changed vs it (including uncommitted work), returning the changed files too.
+func computeGitStats(path string) (GitSummary, []string) {
+	var stats GitSummary
+	var dirty []string
+
+	// Count commits ahead of origin/main
+	out, err := exec.Command("git", "-C", path, "rev-list", "--count", "origin/main..HEAD").Output()
+	if err == nil {
+		fmt.Sscanf(strings.TrimSpace(string(out)), "%d", &stats.Commits)
+	}
+
+	// Using "origin/main" without "..." shows diff including working tree
+	out, err = exec.Command("git", "-C", path, "diff", "--numstat", "origin/main").Output()
+	if err == nil {
+		for _, line := range strings.Split(string(out), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) >= 3 {
+				var a, r int
+				fmt.Sscanf(fields[0], "%d", &a)
+				fmt.Sscanf(fields[1], "%d", &r)
+				stats.Added += a
+				stats.Removed += r
+				dirty = append(dirty, fields[2])
+			}
+		}
+	}
+
+	return stats, dirty
+}
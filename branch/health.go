@@ -25,9 +25,29 @@ type StartupStatus struct {
 	Description string
 }
 
+// LogsDir returns the directory the container writes its logs to.
+func (b *Branch) LogsDir() string {
+	return filepath.Join(b.Path, "rundir", "logs")
+}
+
+// LogFiles returns the names of the .log files in LogsDir, sorted by name.
+func (b *Branch) LogFiles() []string {
+	var files []string
+	entries, err := os.ReadDir(b.LogsDir())
+	if err != nil {
+		return files
+	}
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".log") {
+			files = append(files, e.Name())
+		}
+	}
+	return files
+}
+
 // GetStartupStatus checks the container's startup progress by parsing log files.
 func (b *Branch) GetStartupStatus() StartupStatus {
-	logsDir := filepath.Join(b.Path, "rundir", "logs")
+	logsDir := b.LogsDir()
 
 	// Check build-server.log for progress
 	buildLog := filepath.Join(logsDir, "build-server.log")
@@ -3,28 +3,24 @@ package branch
 import (
 	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/darklang/dark-multi/config"
 	"github.com/darklang/dark-multi/container"
+	"github.com/darklang/dark-multi/log"
 	"github.com/darklang/dark-multi/tmux"
 )
 
-// logToFile writes debug output to /tmp/dark-multi.log
-func logToFile(format string, args ...interface{}) {
-	f, err := os.OpenFile("/tmp/dark-multi.log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return
-	}
-	defer f.Close()
-	msg := fmt.Sprintf(format, args...)
-	f.WriteString(fmt.Sprintf("[branch] %s\n", msg))
-}
+var logger = log.New("branch")
 
 // ensureClaudeSettings ensures ~/.claude/settings.json has theme set on the host
 // This prevents Claude from showing the theme selection prompt on first run
@@ -71,19 +67,19 @@ func Start(b *Branch) error {
 // StartWithProgress starts a branch container with progress callback.
 // The callback receives short status messages suitable for display.
 func StartWithProgress(b *Branch, onProgress func(status string)) error {
-	logToFile("StartWithProgress called for %s", b.Name)
+	logger.Debugf("StartWithProgress called for %s", b.Name)
 
 	if !b.Exists() {
 		return fmt.Errorf("branch %s does not exist", b.Name)
 	}
 
 	if b.IsRunning() {
-		logToFile("Branch %s already running, skipping", b.Name)
+		logger.Debugf("Branch %s already running, skipping", b.Name)
 		return nil // Already running
 	}
 
 	progress := func(s string) {
-		logToFile("Progress: %s", s)
+		logger.Infof("%s: %s", b.Name, s)
 		if onProgress != nil {
 			onProgress(s)
 		}
@@ -96,6 +92,23 @@ func StartWithProgress(b *Branch, onProgress func(status string)) error {
 	// This prevents the theme selection prompt from appearing
 	ensureClaudeSettings()
 
+	if err := container.CheckPortsFree(b); err != nil {
+		return err
+	}
+
+	// Projects without a devcontainer config run from a plain Docker image
+	if !b.HasDevcontainer() {
+		progress("starting container")
+		if err := container.RunPlainContainer(b); err != nil {
+			return err
+		}
+		if err := WaitUntilRunning(b, StartTimeout); err != nil {
+			return err
+		}
+		progress("container ready")
+		return nil
+	}
+
 	progress("preparing container")
 
 	// Generate override config
@@ -104,7 +117,11 @@ func StartWithProgress(b *Branch, onProgress func(status string)) error {
 		return fmt.Errorf("failed to generate override config: %w", err)
 	}
 
-	progress("starting container")
+	if reason := container.PrebuiltMismatch(b.Path); reason == "" {
+		progress("starting container")
+	} else {
+		progress("building image: " + reason)
+	}
 
 	// Start the devcontainer with output capture
 	cmd := exec.Command("devcontainer", "up",
@@ -125,12 +142,11 @@ func StartWithProgress(b *Branch, onProgress func(status string)) error {
 
 	// Parse output for progress
 	scanner := bufio.NewScanner(stdout)
-	stepRegex := regexp.MustCompile(`\[(\d+)/(\d+)\]`)
 	for scanner.Scan() {
 		line := scanner.Text()
-		logToFile("devcontainer: %s", line)
+		logger.Debugf("devcontainer: %s", line)
 		// Extract meaningful status from devcontainer output
-		status := parseDevcontainerLine(line, stepRegex, b.Name)
+		status := parseDevcontainerLine(line, b.Name)
 		if status != "" {
 			progress(status)
 		}
@@ -140,94 +156,33 @@ func StartWithProgress(b *Branch, onProgress func(status string)) error {
 		return fmt.Errorf("failed to start container: %w", err)
 	}
 
+	// devcontainer up can return before docker ps reports the container
+	if err := WaitUntilRunning(b, StartTimeout); err != nil {
+		return err
+	}
+
 	progress("container ready")
 
 	// Note: Don't create tmux session here - wait for auth to complete
 	return nil
 }
 
-// Progress levels in order - higher number = further along
-var progressLevels = map[string]int{
-	"pulling image":        1,
-	"building image":       2,
-	"creating container":   3,
-	"container started":    4,
-	"post-create setup":    5,
-	"post-start setup":     6,
-	"building tree-sitter": 7,
-	"restoring packages":   8,
-	"building F#":          9,
-	"starting build server": 10,
-	"ready":                11,
-}
-
-// currentProgressLevel tracks the highest progress seen per branch
-var currentProgressLevel = make(map[string]int)
+// StartTimeout is how long to wait for a started container to show as running.
+const StartTimeout = 30 * time.Second
 
-// parseDevcontainerLine extracts a short status from devcontainer output.
-// Returns empty string if this status is lower than what we've already seen.
-func parseDevcontainerLine(line string, stepRegex *regexp.Regexp, branchName string) string {
-	line = strings.TrimSpace(line)
-	if line == "" {
-		return ""
-	}
-
-	// Docker build steps: "[5/17] RUN apt-get..."
-	if matches := stepRegex.FindStringSubmatch(line); matches != nil {
-		return fmt.Sprintf("build [%s/%s]", matches[1], matches[2])
-	}
-
-	lower := strings.ToLower(line)
-	var status string
-
-	// Devcontainer lifecycle phases FIRST (these lines may contain metadata with misleading strings)
-	switch {
-	case strings.Contains(lower, "pulling from") || strings.Contains(line, "Pull complete"):
-		status = "pulling image"
-	case strings.Contains(lower, "step ") && strings.Contains(lower, "run ") && !strings.Contains(lower, "docker"):
-		status = "building image"
-	case strings.Contains(lower, "start: run: docker run"):
-		status = "creating container"
-	case strings.Contains(lower, "start: run: docker start"):
-		status = "container started"
-	case strings.Contains(lower, "running the postcreatecommand"):
-		status = "post-create setup"
-	case strings.Contains(lower, "running the poststartcommand"):
-		status = "post-start setup"
-	}
-
-	// Dark-specific build phases (from postStartCommand output) - only if not a lifecycle phase
-	if status == "" {
-		switch {
-		case strings.Contains(lower, "tree-sitter") || strings.Contains(lower, "tree_sitter"):
-			status = "building tree-sitter"
-		case strings.Contains(lower, "dotnet build") || (strings.Contains(lower, "fsdark.sln") && !strings.Contains(lower, "docker")):
-			status = "building F#"
-		case strings.Contains(lower, "dotnet restore"):
-			status = "restoring packages"
-		case strings.Contains(lower, "build-server"):
-			status = "starting build server"
-		case strings.Contains(lower, "shipit ready") || strings.Contains(lower, "ready to ship"):
-			status = "ready"
+// WaitUntilRunning polls until the branch's container is running, or returns
+// an error after timeout (e.g. the container exited right after starting).
+func WaitUntilRunning(b *Branch, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		if id, err := b.ContainerID(); err == nil && id != "" {
+			return nil
 		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("container for %s not running after %s - check 'docker ps -a'", b.Name, timeout)
+		}
+		time.Sleep(200 * time.Millisecond)
 	}
-
-	if status == "" {
-		return ""
-	}
-
-	// Only return if this is higher progress than we've seen
-	level := progressLevels[status]
-	if level > currentProgressLevel[branchName] {
-		currentProgressLevel[branchName] = level
-		return status
-	}
-	return ""
-}
-
-// ResetProgressLevel resets progress tracking for a branch (call when starting fresh)
-func ResetProgressLevel(branchName string) {
-	delete(currentProgressLevel, branchName)
 }
 
 // Stop stops a branch container and cleans up tmux.
@@ -247,13 +202,64 @@ func Stop(b *Branch) error {
 	return nil
 }
 
-// Create creates a new branch, cloning if needed.
-func Create(name string) (*Branch, error) {
-	return CreateWithProgress(name, nil)
+// stopAllWorkers bounds how many containers StopAll stops at once.
+const stopAllWorkers = 4
+
+// StopAll stops every running managed branch, a few at a time, and kills all
+// of their tmux sessions. It returns how many were stopped.
+func StopAll() (int, error) {
+	var running []*Branch
+	for _, b := range GetManagedBranches() {
+		if b.IsRunning() {
+			running = append(running, b)
+		}
+	}
+
+	var mu sync.Mutex
+	var errs []error
+	stopped := 0
+	jobs := make(chan *Branch)
+	var wg sync.WaitGroup
+	for i := 0; i < min(stopAllWorkers, len(running)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range jobs {
+				err := Stop(b)
+				tmux.KillBranchSessions(b.Name)
+				mu.Lock()
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", b.Name, err))
+				} else {
+					stopped++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, b := range running {
+		jobs <- b
+	}
+	close(jobs)
+	wg.Wait()
+
+	return stopped, errors.Join(errs...)
+}
+
+// DefaultBaseRef is what new branches start from when no base is given.
+const DefaultBaseRef = "origin/main"
+
+// Create creates a new branch, cloning if needed. from is the commit, tag or
+// branch to start from; empty means DefaultBaseRef.
+func Create(name, from string) (*Branch, error) {
+	return CreateWithProgress(name, from, nil)
 }
 
 // CreateWithProgress creates a new branch with progress callback.
-func CreateWithProgress(name string, onProgress func(status string)) (*Branch, error) {
+func CreateWithProgress(name, from string, onProgress func(status string)) (*Branch, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
 	b := New(name)
 
 	progress := func(s string) {
@@ -263,6 +269,9 @@ func CreateWithProgress(name string, onProgress func(status string)) (*Branch, e
 	}
 
 	if b.Exists() {
+		if from != "" {
+			return nil, fmt.Errorf("branch %s already exists - a base ref only applies to new branches", name)
+		}
 		if !b.IsManaged() {
 			instanceID := FindNextInstanceID()
 			b.WriteMetadata(instanceID)
@@ -304,24 +313,95 @@ func CreateWithProgress(name string, onProgress func(status string)) (*Branch, e
 	// Ensure remote points to GitHub fork
 	exec.Command("git", "-C", b.Path, "remote", "set-url", "origin", githubFork).Run()
 
-	exec.Command("git", "-C", b.Path, "fetch", "origin").Run()
-	checkoutCmd := exec.Command("git", "-C", b.Path, "checkout", "-b", name, "origin/main")
-	if err := checkoutCmd.Run(); err != nil {
-		exec.Command("git", "-C", b.Path, "checkout", "-b", name, "main").Run()
+	exec.Command("git", "-C", b.Path, "fetch", "--tags", "origin").Run()
+
+	if from != "" {
+		// Validate the ref after fetching so tags and remote branches resolve
+		if err := exec.Command("git", "-C", b.Path, "rev-parse", "--verify", "--quiet", from+"^{commit}").Run(); err != nil {
+			os.RemoveAll(b.Path)
+			return nil, fmt.Errorf("base ref %q not found", from)
+		}
+		if out, err := exec.Command("git", "-C", b.Path, "checkout", "-b", name, from).CombinedOutput(); err != nil {
+			os.RemoveAll(b.Path)
+			return nil, fmt.Errorf("checkout of %s failed: %s", from, strings.TrimSpace(string(out)))
+		}
+	} else {
+		checkoutCmd := exec.Command("git", "-C", b.Path, "checkout", "-b", name, DefaultBaseRef)
+		if err := checkoutCmd.Run(); err != nil {
+			exec.Command("git", "-C", b.Path, "checkout", "-b", name, "main").Run()
+		}
 	}
 
 	b.WriteMetadata(instanceID)
 	return b, nil
 }
 
+// CloneFrom creates a new branch from another branch's HEAD. Only committed
+// work is carried over; untracked files like .claude-task/ are left behind.
+func CloneFrom(source *Branch, newName string) (*Branch, error) {
+	if err := ValidateName(newName); err != nil {
+		return nil, err
+	}
+	if !source.Exists() {
+		return nil, fmt.Errorf("branch %s does not exist", source.Name)
+	}
+
+	b := New(newName)
+	if b.Exists() || b.IsManaged() {
+		return nil, fmt.Errorf("branch %s already exists", newName)
+	}
+
+	// Keep pushing to the same fork as the source
+	remote := config.GetGitHubFork()
+	if out, err := exec.Command("git", "-C", source.Path, "remote", "get-url", "origin").Output(); err == nil {
+		remote = strings.TrimSpace(string(out))
+	}
+
+	instanceID := FindNextInstanceID()
+
+	if out, err := exec.Command("git", "clone", source.Path, b.Path).CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("clone failed: %s", strings.TrimSpace(string(out)))
+	}
+	if remote != "" {
+		exec.Command("git", "-C", b.Path, "remote", "set-url", "origin", remote).Run()
+		exec.Command("git", "-C", b.Path, "fetch", "origin").Run()
+	}
+	if out, err := exec.Command("git", "-C", b.Path, "checkout", "-b", newName).CombinedOutput(); err != nil {
+		os.RemoveAll(b.Path)
+		return nil, fmt.Errorf("checkout failed: %s", strings.TrimSpace(string(out)))
+	}
+
+	if err := b.WriteMetadata(instanceID); err != nil {
+		return nil, err
+	}
+	b.SetMetadata("CLONED_FROM", source.Name)
+	return b, nil
+}
+
 // Remove removes a branch entirely.
 func Remove(b *Branch) error {
+	// Never RemoveAll a path derived from a bad name (e.g. "" would be DarkRoot itself)
+	if err := checkSafePath(b.Name); err != nil {
+		return err
+	}
+
 	Stop(b)
 	tmux.KillBranchSession(b.Name)
 	container.RemoveContainersByLabel(fmt.Sprintf("dark-dev-container=%s", b.Name))
 
-	overrideDir := filepath.Join(config.ConfigDir, "overrides", b.Name)
-	os.RemoveAll(overrideDir)
+	// The nuget and extension caches outlive the container; reclaim them too,
+	// unless the name also fits another branch (insiders-foo vs foo's insiders)
+	archived := archivedNames()
+	for _, v := range container.BranchVolumes(b.Name) {
+		if volumeOwned(v, b.Name, archived) {
+			continue
+		}
+		if err := container.RemoveVolume(v); err != nil && !strings.Contains(err.Error(), "no such volume") {
+			logger.Warnf("removing volume %s: %v", v, err)
+		}
+	}
+
+	os.RemoveAll(b.OverrideDir)
 
 	if err := os.RemoveAll(b.Path); err != nil {
 		return fmt.Errorf("failed to remove files: %w", err)
@@ -329,3 +409,170 @@ func Remove(b *Branch) error {
 
 	return nil
 }
+
+// Renumbered records an instance ID change made by Compact.
+type Renumbered struct {
+	Name  string
+	OldID int
+	NewID int
+}
+
+// Compact moves stopped branches down into instance IDs freed by removed
+// branches, lowest first, keeping port ranges tight. Running branches keep
+// their IDs. A renumbered branch's old container is removed, since its port
+// mappings are fixed; the next start recreates it on the new ports.
+func Compact() ([]Renumbered, error) {
+	branches := GetManagedBranches()
+	sort.Slice(branches, func(i, j int) bool {
+		return branches[i].InstanceID() < branches[j].InstanceID()
+	})
+
+	used := usedInstanceIDs()
+	var changes []Renumbered
+	for _, b := range branches {
+		oldID := b.InstanceID()
+		if oldID == 0 || b.IsRunning() {
+			continue
+		}
+		newID := 1
+		for used[newID] {
+			newID++
+		}
+		if newID >= oldID {
+			continue
+		}
+
+		tmux.KillBranchSessions(b.Name)
+		container.RemoveContainersByLabel(fmt.Sprintf("dark-dev-container=%s", b.Name))
+		if err := b.SetMetadata("ID", strconv.Itoa(newID)); err != nil {
+			return changes, err
+		}
+		delete(used, oldID)
+		used[newID] = true
+		changes = append(changes, Renumbered{Name: b.Name, OldID: oldID, NewID: newID})
+	}
+	return changes, nil
+}
+
+// Rename renames a stopped branch: its directory, override dir, metadata and
+// git branch. The old container is removed; the next start recreates it with
+// the new name and label.
+func Rename(b *Branch, newName string) (*Branch, error) {
+	if err := ValidateName(newName); err != nil {
+		return nil, err
+	}
+	if b.IsRunning() {
+		return nil, fmt.Errorf("%s is running - stop it before renaming", b.Name)
+	}
+
+	nb := New(newName)
+	if nb.Exists() || nb.IsManaged() {
+		return nil, fmt.Errorf("branch %s already exists", newName)
+	}
+
+	tmux.KillBranchSessions(b.Name)
+	container.RemoveContainersByLabel(fmt.Sprintf("dark-dev-container=%s", b.Name))
+
+	if err := os.Rename(b.Path, nb.Path); err != nil {
+		return nil, fmt.Errorf("failed to rename directory: %w", err)
+	}
+	if err := os.Rename(b.OverrideDir, nb.OverrideDir); err != nil {
+		// Put the directory back so the branch stays usable under its old name
+		os.Rename(nb.Path, b.Path)
+		return nil, fmt.Errorf("failed to rename override dir: %w", err)
+	}
+	nb.SetMetadata("NAME", newName)
+
+	// Rename the git branch too if it's checked out under the old name
+	if nb.GitBranch() == b.Name {
+		if err := exec.Command("git", "-C", nb.Path, "branch", "-m", b.Name, newName).Run(); err != nil {
+			return nb, fmt.Errorf("renamed, but git branch -m failed: %w", err)
+		}
+	}
+
+	return nb, nil
+}
+
+// Push pushes the branch's checked-out git branch to origin (the configured
+// GitHub fork) and returns a URL for opening a PR, if one can be derived.
+func Push(b *Branch, forceWithLease bool) (string, error) {
+	fork := config.GetGitHubFork()
+	if fork == "" {
+		return "", fmt.Errorf("GitHub fork not configured. Run: multi set-fork git@github.com:USERNAME/dark.git")
+	}
+	if !b.Exists() {
+		return "", fmt.Errorf("branch %s does not exist", b.Name)
+	}
+
+	gitBranch := b.GitBranch()
+	args := []string{"-C", b.Path, "push", "-u", "origin", gitBranch}
+	if forceWithLease {
+		args = append(args, "--force-with-lease")
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git push failed: %w", err)
+	}
+
+	if web := githubWebURL(fork); web != "" {
+		return fmt.Sprintf("%s/pull/new/%s", web, gitBranch), nil
+	}
+	return "", nil
+}
+
+// githubWebURL converts a GitHub remote (SSH or HTTPS) to its web URL.
+// Returns empty string for non-GitHub remotes.
+func githubWebURL(remote string) string {
+	remote = strings.TrimSuffix(strings.TrimSpace(remote), ".git")
+	switch {
+	case strings.HasPrefix(remote, "git@github.com:"):
+		return "https://github.com/" + strings.TrimPrefix(remote, "git@github.com:")
+	case strings.HasPrefix(remote, "https://github.com/"):
+		return remote
+	}
+	return ""
+}
+
+// UpstreamRepo is the GitHub repo PRs are opened against.
+const UpstreamRepo = "darklang/dark"
+
+// CreatePR opens a PR from the branch on the configured fork against
+// upstream main using the gh CLI. An empty title fills title and body from
+// the branch's commits. Returns the PR URL.
+func CreatePR(b *Branch, title, body string) (string, error) {
+	if _, err := exec.LookPath("gh"); err != nil {
+		return "", fmt.Errorf("gh CLI not found. Install from https://cli.github.com")
+	}
+	fork := config.GetGitHubFork()
+	owner := ""
+	if web := githubWebURL(fork); web != "" {
+		owner = strings.Split(strings.TrimPrefix(web, "https://github.com/"), "/")[0]
+	}
+	if owner == "" {
+		return "", fmt.Errorf("GitHub fork not configured. Run: multi set-fork git@github.com:USERNAME/dark.git")
+	}
+
+	args := []string{"pr", "create",
+		"--repo", UpstreamRepo,
+		"--base", "main",
+		"--head", fmt.Sprintf("%s:%s", owner, b.GitBranch()),
+	}
+	if title == "" {
+		args = append(args, "--fill")
+	} else {
+		args = append(args, "--title", title, "--body", body)
+	}
+
+	cmd := exec.Command("gh", args...)
+	cmd.Dir = b.Path
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("gh pr create failed: %s", strings.TrimSpace(string(out)))
+	}
+
+	// gh prints the PR URL as the last line
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	return lines[len(lines)-1], nil
+}
@@ -0,0 +1,142 @@
+package branch
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/darklang/dark-multi/config"
+	"github.com/darklang/dark-multi/text"
+)
+
+// progressPhase maps devcontainer output lines to a short status label.
+// Higher levels are further along; progress never moves backwards.
+type progressPhase struct {
+	Pattern *regexp.Regexp
+	Exclude *regexp.Regexp // optional: skip lines that also match this
+	Label   string
+	Level   int
+}
+
+// builtinPhases are checked in order; the first match wins. Devcontainer
+// lifecycle phases come first because their lines may contain metadata
+// with misleading strings (e.g. "dotnet build" inside a JSON blob).
+var builtinPhases = []progressPhase{
+	// Devcontainer lifecycle
+	{Pattern: regexp.MustCompile(`(?i)pulling from|pull complete`), Label: "pulling image", Level: 10},
+	{Pattern: regexp.MustCompile(`(?i)step \d+.*run `), Exclude: regexp.MustCompile(`(?i)docker`), Label: "building image", Level: 20},
+	{Pattern: regexp.MustCompile(`(?i)installing feature|feature .*install`), Label: "installing features", Level: 25},
+	{Pattern: regexp.MustCompile(`(?i)start: run: docker run`), Label: "creating container", Level: 30},
+	{Pattern: regexp.MustCompile(`(?i)start: run: docker start`), Label: "container started", Level: 40},
+	{Pattern: regexp.MustCompile(`(?i)running the postcreatecommand`), Label: "post-create setup", Level: 50},
+	{Pattern: regexp.MustCompile(`(?i)npm (install|ci)\b|added \d+ packages`), Label: "npm install", Level: 55},
+	{Pattern: regexp.MustCompile(`(?i)running the poststartcommand`), Label: "post-start setup", Level: 60},
+	{Pattern: regexp.MustCompile(`(?i)installing extension|extensions? install`), Label: "installing extensions", Level: 65},
+
+	// Dark-specific build phases (from postStartCommand output)
+	{Pattern: regexp.MustCompile(`(?i)tree[-_]sitter`), Label: "building tree-sitter", Level: 70},
+	{Pattern: regexp.MustCompile(`(?i)dotnet build|fsdark\.sln`), Exclude: regexp.MustCompile(`(?i)docker`), Label: "building F#", Level: 90},
+	{Pattern: regexp.MustCompile(`(?i)dotnet restore`), Label: "restoring packages", Level: 80},
+	{Pattern: regexp.MustCompile(`(?i)build-server`), Label: "starting build server", Level: 100},
+	{Pattern: regexp.MustCompile(`(?i)shipit ready|ready to ship`), Label: "ready", Level: 110},
+}
+
+var (
+	// stepRegex matches docker build steps: "[5/17] RUN apt-get..."
+	stepRegex = regexp.MustCompile(`\[(\d+)/(\d+)\]`)
+	// logPrefixRegex strips devcontainer CLI timing prefixes like "[1234 ms]"
+	logPrefixRegex = regexp.MustCompile(`^\[\d+ ms\]\s*`)
+
+	phasesOnce sync.Once
+	phases     []progressPhase
+
+	// currentProgressLevel tracks the highest progress seen per branch
+	progressMu           sync.Mutex
+	currentProgressLevel = make(map[string]int)
+)
+
+// loadPhases returns user phases from progress-phases.json followed by the built-ins.
+// The file is a JSON array of {"pattern": "...", "label": "...", "level": N};
+// built-in levels run from 10 to 110 in steps of 10.
+func loadPhases() []progressPhase {
+	phasesOnce.Do(func() {
+		var custom []struct {
+			Pattern string `json:"pattern"`
+			Label   string `json:"label"`
+			Level   int    `json:"level"`
+		}
+		path := filepath.Join(config.ConfigDir, "progress-phases.json")
+		if data, err := os.ReadFile(path); err == nil {
+			if err := json.Unmarshal(data, &custom); err != nil {
+				logger.Warnf("ignoring %s: %v", path, err)
+			}
+		}
+		for _, c := range custom {
+			re, err := regexp.Compile(c.Pattern)
+			if err != nil || c.Label == "" {
+				logger.Warnf("ignoring progress phase %q: invalid pattern or empty label", c.Pattern)
+				continue
+			}
+			phases = append(phases, progressPhase{Pattern: re, Label: c.Label, Level: c.Level})
+		}
+		phases = append(phases, builtinPhases...)
+	})
+	return phases
+}
+
+// parseDevcontainerLine extracts a short status from devcontainer output.
+// Returns empty string if this status is lower than what we've already seen.
+// Unrecognized lines that look like progress are shown as "working: <line>".
+func parseDevcontainerLine(line string, branchName string) string {
+	line = strings.TrimSpace(logPrefixRegex.ReplaceAllString(strings.TrimSpace(line), ""))
+	if line == "" {
+		return ""
+	}
+
+	// Docker build steps: "[5/17] RUN apt-get..."
+	if matches := stepRegex.FindStringSubmatch(line); matches != nil {
+		return fmt.Sprintf("build [%s/%s]", matches[1], matches[2])
+	}
+
+	for _, phase := range loadPhases() {
+		if !phase.Pattern.MatchString(line) {
+			continue
+		}
+		if phase.Exclude != nil && phase.Exclude.MatchString(line) {
+			continue
+		}
+
+		// Only return if this is higher progress than we've seen
+		progressMu.Lock()
+		defer progressMu.Unlock()
+		if phase.Level > currentProgressLevel[branchName] {
+			currentProgressLevel[branchName] = phase.Level
+			return phase.Label
+		}
+		return ""
+	}
+
+	if isInterestingLine(line) {
+		return "working: " + text.Truncate(line, 40)
+	}
+	return ""
+}
+
+// isInterestingLine filters out JSON blobs, separators and other noise.
+func isInterestingLine(line string) bool {
+	if len(line) < 8 || strings.ContainsAny(line[:1], "{}[]\"-=#*") {
+		return false
+	}
+	return !strings.Contains(line, "{")
+}
+
+// ResetProgressLevel resets progress tracking for a branch (call when starting fresh)
+func ResetProgressLevel(branchName string) {
+	progressMu.Lock()
+	defer progressMu.Unlock()
+	delete(currentProgressLevel, branchName)
+}
@@ -35,14 +35,21 @@ type Message struct {
 				Pattern     string `json:"pattern"`
 			} `json:"input"`
 		} `json:"content"`
+		Usage struct {
+			InputTokens              int `json:"input_tokens"`
+			OutputTokens             int `json:"output_tokens"`
+			CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+			CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+		} `json:"usage"`
 	} `json:"message"`
 }
 
-// GetStatus returns Claude's status for a given branch path.
-func GetStatus(branchPath string) *Status {
+// mostRecentConversation returns the most recently modified conversation
+// file for a branch path, or empty string if there is none.
+func mostRecentConversation(branchPath string) (string, os.FileInfo) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return &Status{State: "idle"}
+		return "", nil
 	}
 
 	// Claude encodes paths: /home/stachu/code/dark/main -> -home-stachu-code-dark-main
@@ -54,26 +61,42 @@ func GetStatus(branchPath string) *Status {
 	// Find .jsonl conversation files
 	files, err := filepath.Glob(filepath.Join(projectDir, "*.jsonl"))
 	if err != nil || len(files) == 0 {
-		return &Status{State: "idle"}
+		return "", nil
 	}
 
 	// Find most recent file by modification time
 	var mostRecent string
-	var mostRecentTime time.Time
+	var mostRecentInfo os.FileInfo
 	for _, f := range files {
 		info, err := os.Stat(f)
 		if err != nil {
 			continue
 		}
-		if info.ModTime().After(mostRecentTime) {
+		if mostRecentInfo == nil || info.ModTime().After(mostRecentInfo.ModTime()) {
 			mostRecent = f
-			mostRecentTime = info.ModTime()
+			mostRecentInfo = info
 		}
 	}
+	return mostRecent, mostRecentInfo
+}
+
+// LastActivity returns when a branch's most recent conversation was last
+// written, or the zero time if there is none.
+func LastActivity(branchPath string) time.Time {
+	_, info := mostRecentConversation(branchPath)
+	if info == nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
 
+// GetStatus returns Claude's status for a given branch path.
+func GetStatus(branchPath string) *Status {
+	mostRecent, info := mostRecentConversation(branchPath)
 	if mostRecent == "" {
 		return &Status{State: "idle"}
 	}
+	mostRecentTime := info.ModTime()
 
 	// Read last message from file
 	lastMsg, lastTool, lastRole := readLastMessage(mostRecent)
@@ -176,12 +199,13 @@ func readLastMessage(filepath string) (content string, toolName string, role str
 
 // truncate shortens a string to maxLen, adding "..." if truncated.
 func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
+	runes := []rune(s)
+	if len(runes) <= maxLen {
 		return s
 	}
 
 	// Try to break at a word boundary
-	truncated := s[:maxLen-3]
+	truncated := string(runes[:maxLen-3])
 	if lastSpace := strings.LastIndex(truncated, " "); lastSpace > maxLen/2 {
 		truncated = truncated[:lastSpace]
 	}
@@ -0,0 +1,97 @@
+package claude
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/darklang/dark-multi/log"
+)
+
+var logger = log.New("claude")
+
+// usageCacheEntry holds running usage totals for a conversation file and how
+// far into it they've been summed, so only appended lines are read.
+type usageCacheEntry struct {
+	offset       int64 // bytes summed so far, always at a line boundary
+	inputTokens  int
+	outputTokens int
+}
+
+var (
+	usageMu    sync.Mutex
+	usageCache = make(map[string]usageCacheEntry)
+)
+
+// GetUsage returns token usage summed across the most recent conversation for
+// a branch. Input counts uncached input plus cache writes; cache reads are
+// ignored since they're billed at a small fraction of the input rate.
+func GetUsage(branchPath string) (inputTokens, outputTokens int) {
+	path, info := mostRecentConversation(branchPath)
+	if path == "" {
+		return 0, 0
+	}
+
+	usageMu.Lock()
+	entry := usageCache[path]
+	usageMu.Unlock()
+
+	if info.Size() < entry.offset {
+		// Rewritten rather than appended to; start over
+		entry = usageCacheEntry{}
+	}
+	if info.Size() > entry.offset {
+		entry = sumUsage(path, entry)
+		usageMu.Lock()
+		usageCache[path] = entry
+		usageMu.Unlock()
+	}
+	return entry.inputTokens, entry.outputTokens
+}
+
+// EstimateCost returns a rough dollar cost for token counts at per-million-token rates.
+func EstimateCost(inputTokens, outputTokens int, inputPerMTok, outputPerMTok float64) float64 {
+	return float64(inputTokens)/1e6*inputPerMTok + float64(outputTokens)/1e6*outputPerMTok
+}
+
+// sumUsage adds the usage blocks of assistant messages appended to a JSONL
+// file since entry.offset. Lines are read whole however long they are (tool
+// results can be megabytes); a trailing line without a newline is still being
+// written, so it's left for the next call.
+func sumUsage(path string, entry usageCacheEntry) usageCacheEntry {
+	file, err := os.Open(path)
+	if err != nil {
+		return entry
+	}
+	defer file.Close()
+
+	if _, err := file.Seek(entry.offset, io.SeekStart); err != nil {
+		logger.Warnf("seeking %s: %v", path, err)
+		return entry
+	}
+
+	reader := bufio.NewReaderSize(file, 64*1024)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			if err != io.EOF {
+				logger.Warnf("reading %s: %v", path, err)
+			}
+			return entry
+		}
+		entry.offset += int64(len(line))
+
+		var msg Message
+		if err := json.Unmarshal(line, &msg); err != nil {
+			continue
+		}
+		if msg.Type != "assistant" {
+			continue
+		}
+		u := msg.Message.Usage
+		entry.inputTokens += u.InputTokens + u.CacheCreationInputTokens
+		entry.outputTokens += u.OutputTokens
+	}
+}
@@ -2,21 +2,37 @@
 package cli
 
 import (
+	"bufio"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/darklang/dark-multi/branch"
+	"github.com/darklang/dark-multi/claude"
 	"github.com/darklang/dark-multi/config"
+	"github.com/darklang/dark-multi/container"
+	"github.com/darklang/dark-multi/control"
 	"github.com/darklang/dark-multi/dns"
 	"github.com/darklang/dark-multi/inotify"
 	"github.com/darklang/dark-multi/proxy"
+	"github.com/darklang/dark-multi/tmux"
 	"github.com/darklang/dark-multi/tui"
 )
 
 // NewRootCmd creates the root cobra command.
 func NewRootCmd() *cobra.Command {
+	var noProxy bool
+
 	rootCmd := &cobra.Command{
 		Use:   "multi",
 		Short: "Manage multiple Dark devcontainer instances",
@@ -34,6 +50,9 @@ TUI shortcuts:
   enter       Branch details & URLs
   ?           Help`,
 		Run: func(cmd *cobra.Command, args []string) {
+			if noProxy {
+				config.AutoStartProxy = false
+			}
 			if err := tui.Run(); err != nil {
 				fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m %v\n", err)
 				os.Exit(1)
@@ -41,8 +60,11 @@ TUI shortcuts:
 		},
 	}
 
+	rootCmd.Flags().BoolVar(&noProxy, "no-proxy", false, "Don't auto-start the URL proxy with the TUI")
+
 	rootCmd.AddCommand(proxyCmd())
 	rootCmd.AddCommand(setupDNSCmd())
+	rootCmd.AddCommand(uninstallDNSCmd())
 	rootCmd.AddCommand(setupInotifyCmd())
 	rootCmd.AddCommand(lsCmd())
 	rootCmd.AddCommand(newCmd())
@@ -50,11 +72,36 @@ TUI shortcuts:
 	rootCmd.AddCommand(stopCmd())
 	rootCmd.AddCommand(rmCmd())
 	rootCmd.AddCommand(setForkCmd())
+	rootCmd.AddCommand(setEditorCmd())
+	rootCmd.AddCommand(setBaseImageCmd())
+	rootCmd.AddCommand(doctorCmd())
+	rootCmd.AddCommand(ctlCmd())
+	rootCmd.AddCommand(configCmd())
+	rootCmd.AddCommand(statusCmd())
+	rootCmd.AddCommand(archiveCmd())
+	rootCmd.AddCommand(restoreCmd())
+	rootCmd.AddCommand(execCmd())
+	rootCmd.AddCommand(logsCmd())
+	rootCmd.AddCommand(cloneCmd())
+	rootCmd.AddCommand(renameCmd())
+	rootCmd.AddCommand(compactCmd())
+	rootCmd.AddCommand(pruneCmd())
+	rootCmd.AddCommand(limitsCmd())
+	rootCmd.AddCommand(envCmd())
+	rootCmd.AddCommand(pushCmd())
+	rootCmd.AddCommand(prCmd())
+	rootCmd.AddCommand(attachCmd())
 
 	return rootCmd
 }
 
 func proxyCmd() *cobra.Command {
+	var useTLS bool
+	var logRequests bool
+	var follow bool
+	var lines int
+	var port int
+
 	cmd := &cobra.Command{
 		Use:   "proxy <action>",
 		Short: "Manage URL proxy server",
@@ -64,25 +111,58 @@ Actions:
   start   Start proxy in background
   stop    Stop proxy
   status  Check if proxy is running
-  fg      Run proxy in foreground (for debugging)`,
+  fg      Run proxy in foreground (for debugging)
+  logs    Show the access log (-f to follow)
+
+With --tls, HTTPS is also served on DARK_MULTI_PROXY_TLS_PORT using a
+self-signed CA stored in the config dir.
+
+With --log, each request is logged (method, host, path, status, target
+port) to proxy.log in the config dir.`,
 		Args: cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			action := args[0]
 
+			// --port is only saved by start/fg, once the proxy has bound it
+			portSet := cmd.Flags().Changed("port")
+			if portSet && (port < 1 || port > 65535) {
+				fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m invalid port %d: must be 1-65535\n", port)
+				os.Exit(1)
+			}
+			if !portSet {
+				port = config.GetProxyPort()
+			}
+
+			opts := proxy.Options{Log: logRequests, SavePort: portSet}
+			if useTLS {
+				opts.TLSPort = config.ProxyTLSPort
+				if err := proxy.EnsureCA(); err != nil {
+					fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m %v\n", err)
+					os.Exit(1)
+				}
+			}
+
 			switch action {
 			case "start":
 				if pid, running := proxy.IsRunning(); running {
 					fmt.Printf("\033[1;33m!\033[0m Proxy already running (PID %d)\n", pid)
+					if portSet && port != config.GetProxyPort() {
+						fmt.Println("  Run 'multi proxy stop' first to move it to a new port")
+					}
 					return
 				}
 
-				fmt.Printf("\033[0;34m>\033[0m Starting proxy on port %d...\n", config.ProxyPort)
-				pid, err := proxy.Start(config.ProxyPort, true)
+				fmt.Printf("\033[0;34m>\033[0m Starting proxy on port %d...\n", port)
+				pid, err := proxy.Start(port, true, opts)
 				if err != nil {
 					fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m %v\n", err)
 					os.Exit(1)
 				}
 				fmt.Printf("\033[0;32m✓\033[0m Proxy started (PID %d)\n", pid)
+				if useTLS {
+					fmt.Printf("  HTTPS on port %d\n\n", opts.TLSPort)
+					fmt.Println(proxy.TrustInstructions())
+				}
 
 			case "stop":
 				if proxy.Stop() {
@@ -93,22 +173,62 @@ Actions:
 
 			case "status":
 				if pid, running := proxy.IsRunning(); running {
-					fmt.Printf("Proxy running (PID %d) on port %d\n", pid, config.ProxyPort)
+					fmt.Printf("Proxy running (PID %d) on port %d\n", pid, config.GetProxyPort())
 				} else {
 					fmt.Println("Proxy not running")
 				}
 
 			case "fg":
-				fmt.Printf("\033[0;34m>\033[0m Starting proxy on port %d (foreground)...\n", config.ProxyPort)
-				proxy.Start(config.ProxyPort, false)
+				fmt.Printf("\033[0;34m>\033[0m Starting proxy on port %d (foreground)...\n", port)
+				if _, err := proxy.Start(port, false, opts); err != nil {
+					fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m %v\n", err)
+					os.Exit(1)
+				}
+
+			case "logs":
+				data, err := os.ReadFile(config.ProxyLogFile)
+				if err != nil && !follow {
+					fmt.Println("No proxy log yet (start the proxy with --log)")
+					return
+				}
+				fmt.Print(tailLines(string(data), lines))
+				if !follow {
+					return
+				}
+
+				offset := int64(len(data))
+				for {
+					time.Sleep(1 * time.Second)
+					info, err := os.Stat(config.ProxyLogFile)
+					if err != nil || info.Size() == offset {
+						continue
+					}
+					if info.Size() < offset {
+						offset = 0 // Truncated
+					}
+					fh, err := os.Open(config.ProxyLogFile)
+					if err != nil {
+						continue
+					}
+					fh.Seek(offset, io.SeekStart)
+					n, _ := io.Copy(os.Stdout, fh)
+					fh.Close()
+					offset += n
+				}
 
 			default:
-				fmt.Fprintf(os.Stderr, "Unknown action: %s\nUse: start, stop, status, fg\n", action)
+				fmt.Fprintf(os.Stderr, "Unknown action: %s\nUse: start, stop, status, fg, logs\n", action)
 				os.Exit(1)
 			}
 		},
 	}
 
+	cmd.Flags().BoolVar(&useTLS, "tls", false, "Also serve HTTPS with a self-signed certificate")
+	cmd.Flags().BoolVar(&logRequests, "log", false, "Log each request to proxy.log in the config dir")
+	cmd.Flags().BoolVarP(&follow, "follow", "f", false, "With logs: keep printing new requests")
+	cmd.Flags().IntVarP(&lines, "lines", "n", 30, "With logs: number of lines to show (0 for all)")
+	cmd.Flags().IntVar(&port, "port", 0, "Proxy port for start/fg (saved for future runs and URLs once bound)")
+
 	return cmd
 }
 
@@ -117,7 +237,24 @@ func setupDNSCmd() *cobra.Command {
 		Use:   "setup-dns",
 		Short: "Set up wildcard DNS for *.dlio.localhost",
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := dns.Setup(); err != nil {
+			var names []string
+			for _, b := range branch.GetManagedBranches() {
+				names = append(names, b.Name)
+			}
+			if err := dns.Setup(names...); err != nil {
+				fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+}
+
+func uninstallDNSCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "uninstall-dns",
+		Short: "Revert the DNS changes made by setup-dns",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := dns.Teardown(); err != nil {
 				fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m %v\n", err)
 				os.Exit(1)
 			}
@@ -149,12 +286,164 @@ Requires sudo. Only needed on Linux (macOS uses FSEvents).`,
 	}
 }
 
+// branchJSON is the machine-readable branch state printed by ls --json.
+type branchJSON struct {
+	Name        string `json:"name"`
+	Running     bool   `json:"running"`
+	InstanceID  int    `json:"instanceId"`
+	PortBase    int    `json:"portBase"`
+	BwdPortBase int    `json:"bwdPortBase"`
+	Commits     int    `json:"commits"`
+	Added       int    `json:"added"`
+	Removed     int    `json:"removed"`
+	HasChanges  bool   `json:"hasChanges"`
+}
+
+// branchStatusJSON is the machine-readable form of 'multi status'.
+type branchStatusJSON struct {
+	branchJSON
+	Path        string  `json:"path"`
+	GitBranch   string  `json:"gitBranch"`
+	ContainerID string  `json:"containerId,omitempty"`
+	StartedAt   string  `json:"startedAt,omitempty"`
+	Claude      string  `json:"claude"`
+	ClaudeCost  float64 `json:"claudeCost"`
+	CPUs        string  `json:"cpus"`
+	Memory      string  `json:"memory"`
+	Stale       string  `json:"stale,omitempty"`
+	URL         string  `json:"url"`
+}
+
+func statusCmd() *cobra.Command {
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:               "status <name>",
+		Short:             "Show full detail for a branch",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeBranchNames,
+		Run: func(cmd *cobra.Command, args []string) {
+			b := branch.New(args[0])
+			if !b.Exists() {
+				fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m branch %s does not exist\n", args[0])
+				os.Exit(1)
+			}
+
+			commits, added, removed := b.GitStats()
+			cpus, memory := b.ResourceLimits()
+			in, out := claude.GetUsage(b.Path)
+			st := branchStatusJSON{
+				branchJSON: branchJSON{
+					Name:        b.Name,
+					InstanceID:  b.InstanceID(),
+					PortBase:    b.PortBase(),
+					BwdPortBase: b.BwdPortBase(),
+					Commits:     commits,
+					Added:       added,
+					Removed:     removed,
+					HasChanges:  b.HasChanges(),
+				},
+				Path:       b.Path,
+				GitBranch:  b.GitBranch(),
+				Claude:     claude.GetStatus(b.Path).State,
+				ClaudeCost: claude.EstimateCost(in, out, config.InputTokenPrice, config.OutputTokenPrice),
+				CPUs:       cpus,
+				Memory:     memory,
+				URL:        fmt.Sprintf("http://dark-packages.%s.dlio.localhost:%d/ping", b.Name, config.GetProxyPort()),
+			}
+			var startedAt time.Time
+			if id, err := b.ContainerID(); err == nil && id != "" {
+				st.Running = true
+				st.ContainerID = id
+				if t, err := container.StartedAt(id); err == nil {
+					startedAt = t
+					st.StartedAt = t.Format(time.RFC3339)
+				}
+			}
+			if desc, stale := b.Staleness(); stale {
+				st.Stale = desc
+			}
+
+			if asJSON {
+				data, _ := json.MarshalIndent(st, "", "  ")
+				fmt.Println(string(data))
+				return
+			}
+
+			state := "\033[0;31m○ stopped\033[0m"
+			if st.Running {
+				state = "\033[0;32m● running\033[0m"
+			}
+			fmt.Printf("%s  %s\n", b.Name, state)
+			fmt.Printf("  path        %s (git branch %s)\n", st.Path, st.GitBranch)
+			if st.Running {
+				uptime := ""
+				if !startedAt.IsZero() {
+					uptime = fmt.Sprintf(", up %s", time.Since(startedAt).Round(time.Second))
+				}
+				fmt.Printf("  container   %s%s\n", st.ContainerID, uptime)
+			}
+			fmt.Printf("  instance    ID=%d, ports %d+ / %d+\n", st.InstanceID, st.PortBase, st.BwdPortBase)
+			fmt.Printf("  limits      --cpus %s --memory %s\n", st.CPUs, st.Memory)
+			changes := ""
+			if st.HasChanges {
+				changes = " [modified]"
+			}
+			fmt.Printf("  git         %dc +%d/-%d vs main%s\n", st.Commits, st.Added, st.Removed, changes)
+			if st.Stale != "" {
+				fmt.Printf("  stale       %s\n", st.Stale)
+			}
+			fmt.Printf("  claude      %s, ~$%.2f\n", st.Claude, st.ClaudeCost)
+			fmt.Printf("  url         %s\n", st.URL)
+		},
+	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Output machine-readable JSON")
+	return cmd
+}
+
 func lsCmd() *cobra.Command {
-	return &cobra.Command{
+	var asJSON, archived bool
+
+	cmd := &cobra.Command{
 		Use:   "ls",
 		Short: "List all managed branches",
 		Run: func(cmd *cobra.Command, args []string) {
+			if archived {
+				list := branch.ListArchived()
+				if len(list) == 0 {
+					fmt.Println("No archived branches")
+					return
+				}
+				for _, a := range list {
+					fmt.Printf("%-20s archived %s  %s\n", a.Name, a.ArchivedAt.Format("2006-01-02 15:04"), a.Dir)
+				}
+				return
+			}
+
 			branches := branch.GetManagedBranches()
+
+			if asJSON {
+				out := []branchJSON{}
+				for _, b := range branches {
+					commits, added, removed := b.GitStats()
+					out = append(out, branchJSON{
+						Name:        b.Name,
+						Running:     b.IsRunning(),
+						InstanceID:  b.InstanceID(),
+						PortBase:    b.PortBase(),
+						BwdPortBase: b.BwdPortBase(),
+						Commits:     commits,
+						Added:       added,
+						Removed:     removed,
+						HasChanges:  b.HasChanges(),
+					})
+				}
+				data, _ := json.MarshalIndent(out, "", "  ")
+				fmt.Println(string(data))
+				return
+			}
+
 			if len(branches) == 0 {
 				fmt.Println("No branches. Create one with: multi new <name>")
 				return
@@ -168,32 +457,80 @@ func lsCmd() *cobra.Command {
 			}
 		},
 	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Output machine-readable JSON")
+	cmd.Flags().BoolVar(&archived, "archived", false, "List archived branches instead")
+	return cmd
 }
 
 func newCmd() *cobra.Command {
-	return &cobra.Command{
+	var from string
+	var dryRun bool
+
+	cmd := &cobra.Command{
 		Use:   "new <name>",
 		Short: "Create a new branch",
 		Args:  cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			name := args[0]
 
-			fmt.Printf("Creating %s...\n", name)
-			b, err := branch.Create(name)
+			if dryRun {
+				if err := branch.ValidateName(name); err != nil {
+					fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m %v\n", err)
+					os.Exit(1)
+				}
+				b := branch.New(name)
+				source := branch.FindSourceRepo()
+				if b.Exists() {
+					source = b.Path
+				}
+				base := from
+				if base == "" {
+					base = branch.DefaultBaseRef
+				}
+				instanceID := b.InstanceID()
+				if !b.IsManaged() {
+					instanceID = branch.FindNextInstanceID()
+				}
+				printDryRun(b, instanceID, source, base)
+				return
+			}
+
+			if from != "" {
+				fmt.Printf("Creating %s from %s...\n", name, from)
+			} else {
+				fmt.Printf("Creating %s...\n", name)
+			}
+			b, err := branch.Create(name, from)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m %v\n", err)
 				os.Exit(1)
 			}
 			fmt.Printf("\033[0;32m✓\033[0m Created %s (ID=%d)\n", name, b.InstanceID())
+
+			// Windows has no wildcard DNS, so each branch needs hosts entries
+			if err := dns.AddBranchHosts(name); err != nil {
+				fmt.Printf("\033[1;33m!\033[0m Could not add hosts entries: %v\n", err)
+				fmt.Println("  Re-run 'multi setup-dns' as Administrator")
+			}
 		},
 	}
+
+	cmd.Flags().StringVar(&from, "from", "", "Commit, tag or branch to start from (default "+branch.DefaultBaseRef+")")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be created and started without doing it")
+
+	return cmd
 }
 
 func startCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "start <name>",
-		Short: "Start a branch's container",
-		Args:  cobra.ExactArgs(1),
+	var dryRun bool
+	var pullBase bool
+
+	cmd := &cobra.Command{
+		Use:               "start <name>",
+		Short:             "Start a branch's container",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeBranchNames,
 		Run: func(cmd *cobra.Command, args []string) {
 			name := args[0]
 			b := branch.New(name)
@@ -203,11 +540,36 @@ func startCmd() *cobra.Command {
 				os.Exit(1)
 			}
 
+			if dryRun {
+				printDryRun(b, b.InstanceID(), b.Path, "")
+				return
+			}
+
 			if b.IsRunning() {
 				fmt.Printf("\033[1;33m!\033[0m %s is already running\n", name)
 				return
 			}
 
+			if b.HasDevcontainer() {
+				image, prebuilt := container.PrebuiltImage(b.Path)
+				if pullBase {
+					fmt.Printf("\033[0;34m>\033[0m Pulling %s...\n", image)
+					if err := container.PullBaseImage(); err != nil {
+						fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m %v\n", err)
+						os.Exit(1)
+					}
+					// A newer tag may have been built from this Dockerfile
+					if !prebuilt {
+						adoptBaseImageHash(image)
+						_, prebuilt = container.PrebuiltImage(b.Path)
+					}
+				}
+				if !prebuilt {
+					fmt.Printf("\033[1;33m!\033[0m Not using the pre-built base image: %s - building locally (slow)\n", container.PrebuiltMismatch(b.Path))
+					fmt.Println("  Point 'multi set-base-image' at a newer tag and pass --pull-base, or see 'multi doctor'")
+				}
+			}
+
 			fmt.Printf("Starting %s...\n", name)
 			if err := branch.Start(b); err != nil {
 				fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m %v\n", err)
@@ -216,14 +578,102 @@ func startCmd() *cobra.Command {
 			fmt.Printf("\033[0;32m✓\033[0m Started %s\n", name)
 		},
 	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show how the branch would be started without doing it")
+	cmd.Flags().BoolVar(&pullBase, "pull-base", false, "Pull the pre-built base image before starting, adopting its Dockerfile hash")
+	return cmd
+}
+
+// adoptBaseImageHash makes a freshly pulled base image's Dockerfile hash
+// label the expected hash, so branches built from that Dockerfile use it.
+func adoptBaseImageHash(image string) {
+	hash := container.ImageDockerfileHash(image)
+	if hash == "" || hash == config.GetBaseDockerfileHash() {
+		return
+	}
+	if err := config.SetBaseImage(image, hash); err != nil {
+		fmt.Fprintf(os.Stderr, "\033[1;33m!\033[0m can't save base image hash: %v\n", err)
+		return
+	}
+	fmt.Printf("\033[0;32m✓\033[0m %s was built from Dockerfile %.7s\n", image, hash)
+}
+
+// printDryRun prints how a branch would be created and started: where it
+// clones from, its instance ID and ports, and which image it would use.
+// base is the ref a new branch starts from; empty for existing branches.
+func printDryRun(b *branch.Branch, instanceID int, source, base string) {
+	dim := func(s string) string { return "\033[0;90m" + s + "\033[0m" }
+
+	fmt.Printf("\033[0;34m>\033[0m Dry run for %s (nothing will be changed)\n", b.Name)
+	if base != "" && !b.Exists() {
+		if source == "" {
+			source = dim("(none found - set DARK_SOURCE or clone main)")
+		}
+		fmt.Printf("  clone from  %s\n", source)
+		fmt.Printf("  base        %s\n", base)
+		fmt.Printf("  path        %s\n", b.Path)
+	} else {
+		fmt.Printf("  path        %s %s\n", b.Path, dim("(exists)"))
+	}
+
+	fmt.Printf("  instance    ID=%d\n", instanceID)
+	fmt.Printf("  ports       %d-%d -> 10011-10030 (test), %d-%d -> 11001-11002 (bwd)\n",
+		branch.PortBaseFor(instanceID), branch.PortBaseFor(instanceID)+19,
+		branch.BwdPortBaseFor(instanceID), branch.BwdPortBaseFor(instanceID)+1)
+	fmt.Printf("  override    %s\n", container.GetOverrideConfigPath(b.Name))
+
+	cpus, memory := b.ResourceLimits()
+	fmt.Printf("  limits      --cpus %s --memory %s\n", cpus, memory)
+
+	// A new branch's Dockerfile will match its source checkout's
+	imagePath := source
+	if b.Exists() {
+		imagePath = b.Path
+	}
+	if _, err := os.Stat(filepath.Join(imagePath, ".devcontainer", "devcontainer.json")); err != nil {
+		image := config.GetDockerImage()
+		if image == "" {
+			image = dim("(none - set DARK_MULTI_DOCKER_IMAGE)")
+		}
+		fmt.Printf("  image       %s %s\n", image, dim("(no devcontainer.json, plain docker)"))
+	} else if image, prebuilt := container.PrebuiltImage(imagePath); prebuilt {
+		fmt.Printf("  image       %s %s\n", image, dim("(pre-built, Dockerfile matches)"))
+	} else {
+		fmt.Printf("  image       %s\n", dim("built locally (Dockerfile differs from pre-built base)"))
+	}
 }
 
 func stopCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "stop <name>",
-		Short: "Stop a branch's container",
-		Args:  cobra.ExactArgs(1),
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:               "stop <name> | --all",
+		Short:             "Stop a branch's container",
+		ValidArgsFunction: completeBranchNames,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if all {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
 		Run: func(cmd *cobra.Command, args []string) {
+			if all {
+				fmt.Println("Stopping all running branches...")
+				stopped, err := branch.StopAll()
+				if stopped == 0 && err == nil {
+					fmt.Println("\033[1;33m!\033[0m No running branches")
+					return
+				}
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m %v\n", err)
+				}
+				fmt.Printf("\033[0;32m✓\033[0m Stopped %d branches\n", stopped)
+				if err != nil {
+					os.Exit(1)
+				}
+				return
+			}
+
 			name := args[0]
 			b := branch.New(name)
 
@@ -245,13 +695,19 @@ func stopCmd() *cobra.Command {
 			fmt.Printf("\033[0;32m✓\033[0m Stopped %s\n", name)
 		},
 	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Stop every running branch")
+	return cmd
 }
 
 func rmCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "rm <name>",
-		Short: "Remove a branch entirely",
-		Args:  cobra.ExactArgs(1),
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:               "rm <name>",
+		Short:             "Remove a branch entirely",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeBranchNames,
 		Run: func(cmd *cobra.Command, args []string) {
 			name := args[0]
 			b := branch.New(name)
@@ -261,6 +717,23 @@ func rmCmd() *cobra.Command {
 				os.Exit(1)
 			}
 
+			if !force {
+				if b.HasChanges() {
+					fmt.Printf("\033[1;33m!\033[0m '%s' has uncommitted changes!\n", name)
+					fmt.Printf("  Keep them with: multi archive %s\n", name)
+				}
+				if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+					fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m not a terminal - use -f to remove %s without confirmation\n", name)
+					os.Exit(1)
+				}
+				fmt.Printf("Delete '%s'? [y/N] ", name)
+				answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+				if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+					fmt.Println("Cancelled")
+					return
+				}
+			}
+
 			fmt.Printf("Removing %s...\n", name)
 			if err := branch.Remove(b); err != nil {
 				fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m %v\n", err)
@@ -269,6 +742,729 @@ func rmCmd() *cobra.Command {
 			fmt.Printf("\033[0;32m✓\033[0m Removed %s\n", name)
 		},
 	}
+
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "Remove without confirmation")
+	return cmd
+}
+
+func archiveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "archive <name>",
+		Short:             "Stop a branch and move its files to the archive",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeBranchNames,
+		Run: func(cmd *cobra.Command, args []string) {
+			b := branch.New(args[0])
+
+			fmt.Printf("Archiving %s...\n", b.Name)
+			a, err := branch.Archive(b)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("\033[0;32m✓\033[0m Archived %s to %s\n", b.Name, a.Dir)
+			fmt.Printf("  Restore with: multi restore %s\n", b.Name)
+		},
+	}
+}
+
+func restoreCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore <name>",
+		Short: "Restore the most recent archive of a branch",
+		Args:  cobra.ExactArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			var names []string
+			for _, a := range branch.ListArchived() {
+				names = append(names, a.Name)
+			}
+			return names, cobra.ShellCompDirectiveNoFileComp
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			b, err := branch.Restore(args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("\033[0;32m✓\033[0m Restored %s (ID=%d)\n", b.Name, b.InstanceID())
+		},
+	}
+}
+
+func execCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "exec <name> <cmd...>",
+		Short:             "Run a command inside a branch's container",
+		Args:              cobra.MinimumNArgs(2),
+		ValidArgsFunction: completeBranchNames,
+		Run: func(cmd *cobra.Command, args []string) {
+			name := args[0]
+			b := branch.New(name)
+
+			containerID, _ := b.ContainerID()
+			if containerID == "" {
+				fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m %s is not running. Start it with: multi start %s\n", name, name)
+				os.Exit(1)
+			}
+
+			// Only allocate a TTY when attached to one, so output can be piped
+			dockerArgs := []string{"exec", "-i"}
+			if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+				dockerArgs = append(dockerArgs, "-t")
+			}
+			dockerArgs = append(dockerArgs, "-w", "/home/dark/app", containerID)
+			dockerArgs = append(dockerArgs, args[1:]...)
+
+			c := exec.Command("docker", dockerArgs...)
+			c.Stdin = os.Stdin
+			c.Stdout = os.Stdout
+			c.Stderr = os.Stderr
+			if err := c.Run(); err != nil {
+				if exitErr, ok := err.(*exec.ExitError); ok {
+					os.Exit(exitErr.ExitCode())
+				}
+				fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	// Stop flag parsing at the branch name so the command's own flags pass through
+	cmd.Flags().SetInterspersed(false)
+	return cmd
+}
+
+func logsCmd() *cobra.Command {
+	var follow bool
+	var file string
+	var lines int
+
+	cmd := &cobra.Command{
+		Use:   "logs <name>",
+		Short: "Show a branch's container build/run logs",
+		Long: `Show the tail of a branch's rundir/logs/*.log files.
+
+Examples:
+  multi logs main                          # Tail all log files
+  multi logs main --file build-server.log  # Tail one file
+  multi logs main -f | grep error          # Follow and filter`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeBranchNames,
+		Run: func(cmd *cobra.Command, args []string) {
+			name := args[0]
+			b := branch.New(name)
+
+			if !b.Exists() {
+				fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m branch %s does not exist\n", name)
+				os.Exit(1)
+			}
+
+			listFiles := func() []string {
+				if file != "" {
+					return []string{file}
+				}
+				return b.LogFiles()
+			}
+
+			files := listFiles()
+			if len(files) == 0 && !follow {
+				fmt.Printf("No log files yet in %s\n", b.LogsDir())
+				return
+			}
+
+			// Print the tail of each file, remembering where we stopped
+			offsets := make(map[string]int64)
+			lastFile := ""
+			for _, f := range files {
+				data, err := os.ReadFile(filepath.Join(b.LogsDir(), f))
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m %v\n", err)
+					os.Exit(1)
+				}
+				if len(files) > 1 {
+					fmt.Printf("==> %s <==\n", f)
+				}
+				fmt.Print(tailLines(string(data), lines))
+				offsets[f] = int64(len(data))
+				lastFile = f
+			}
+
+			if !follow {
+				return
+			}
+
+			// Poll for appended content, like the TUI's log viewer
+			for {
+				time.Sleep(1 * time.Second)
+				files = listFiles()
+				for _, f := range files {
+					path := filepath.Join(b.LogsDir(), f)
+					info, err := os.Stat(path)
+					if err != nil {
+						continue
+					}
+					if info.Size() < offsets[f] {
+						offsets[f] = 0 // Truncated or rotated
+					}
+					if info.Size() == offsets[f] {
+						continue
+					}
+					fh, err := os.Open(path)
+					if err != nil {
+						continue
+					}
+					fh.Seek(offsets[f], io.SeekStart)
+					if len(files) > 1 && f != lastFile {
+						fmt.Printf("\n==> %s <==\n", f)
+					}
+					n, _ := io.Copy(os.Stdout, fh)
+					fh.Close()
+					offsets[f] += n
+					lastFile = f
+				}
+			}
+		},
+	}
+
+	cmd.Flags().BoolVarP(&follow, "follow", "f", false, "Keep printing new log output")
+	cmd.Flags().StringVar(&file, "file", "", "Only show this log file (e.g. build-server.log)")
+	cmd.Flags().IntVarP(&lines, "lines", "n", 30, "Number of lines to show from the end of each file (0 for all)")
+	return cmd
+}
+
+// tailLines returns the last n lines of s, ending with a newline. n <= 0
+// returns every line.
+func tailLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if n > 0 && len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	if len(lines) == 1 && lines[0] == "" {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// completeBranchNames completes the first argument with managed branch names.
+func completeBranchNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var names []string
+	for _, b := range branch.GetManagedBranches() {
+		if strings.HasPrefix(b.Name, toComplete) {
+			names = append(names, b.Name)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+func cloneCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "clone <source> <new>",
+		Short:             "Create a branch from another branch's committed work",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeBranchNames,
+		Run: func(cmd *cobra.Command, args []string) {
+			source := branch.New(args[0])
+			newName := args[1]
+
+			fmt.Printf("Cloning %s to %s...\n", source.Name, newName)
+			b, err := branch.CloneFrom(source, newName)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("\033[0;32m✓\033[0m Created %s from %s (ID=%d)\n", b.Name, source.Name, b.InstanceID())
+			if source.HasChanges() {
+				fmt.Printf("\033[1;33m!\033[0m %s has uncommitted changes that were not copied\n", source.Name)
+			}
+
+			if err := dns.AddBranchHosts(newName); err != nil {
+				fmt.Printf("\033[1;33m!\033[0m Could not add hosts entries: %v\n", err)
+			}
+		},
+	}
+}
+
+func compactCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "compact",
+		Short: "Renumber stopped branches into freed instance IDs",
+		Long: `Renumber stopped branches into instance IDs freed by removed branches,
+so port ranges stay low. Running branches are left alone; a renumbered
+branch gets new ports on its next start.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			changes, err := branch.Compact()
+			for _, c := range changes {
+				b := branch.New(c.Name)
+				fmt.Printf("\033[0;32m✓\033[0m %s: ID %d -> %d (ports %d+ / %d+)\n", c.Name, c.OldID, c.NewID, b.PortBase(), b.BwdPortBase())
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m %v\n", err)
+				os.Exit(1)
+			}
+			if len(changes) == 0 {
+				fmt.Println("Instance IDs are already compact")
+			}
+		},
+	}
+}
+
+func pruneCmd() *cobra.Command {
+	var dryRun bool
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove docker volumes left behind by removed branches",
+		Long: `Remove the per-branch nuget and VS Code extension volumes whose branch
+no longer exists. Volumes still attached to a container are skipped.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			orphans, err := branch.OrphanedVolumes()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m %v\n", err)
+				os.Exit(1)
+			}
+			if len(orphans) == 0 {
+				fmt.Println("No orphaned volumes")
+				return
+			}
+
+			// Sizes are informational; prune still works if df fails
+			sizes, _ := container.VolumeSizes()
+			var freed int64
+			for _, v := range orphans {
+				size := container.FormatBytes(sizes[v])
+				if dryRun {
+					fmt.Printf("Would remove %s (%s)\n", v, size)
+					freed += sizes[v]
+					continue
+				}
+				if err := container.RemoveVolume(v); err != nil {
+					fmt.Printf("\033[1;33m!\033[0m %s: %v\n", v, err)
+					continue
+				}
+				fmt.Printf("\033[0;32m✓\033[0m Removed %s (%s)\n", v, size)
+				freed += sizes[v]
+			}
+			if dryRun {
+				fmt.Printf("Would free %s\n", container.FormatBytes(freed))
+			} else {
+				fmt.Printf("Freed %s\n", container.FormatBytes(freed))
+			}
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "List orphaned volumes without removing them")
+	return cmd
+}
+
+func renameCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "rename <old> <new>",
+		Short:             "Rename a stopped branch",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeBranchNames,
+		Run: func(cmd *cobra.Command, args []string) {
+			oldName, newName := args[0], args[1]
+			b := branch.New(oldName)
+
+			if !b.Exists() {
+				fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m branch %s does not exist\n", oldName)
+				os.Exit(1)
+			}
+
+			if _, err := branch.Rename(b, newName); err != nil {
+				fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("\033[0;32m✓\033[0m Renamed %s to %s\n", oldName, newName)
+		},
+	}
+}
+
+// envKeyRegex matches valid environment variable names.
+var envKeyRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func envCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "env <branch> [KEY=VALUE ...]",
+		Short: "List or set extra container env vars for a branch",
+		Long: `List or set extra container env vars for a branch.
+
+Without KEY=VALUE args, lists the branch's env vars. KEY= removes a var.
+Changes apply on next start.`,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeBranchNames,
+		Run: func(cmd *cobra.Command, args []string) {
+			b := branch.New(args[0])
+			if !b.Exists() {
+				fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m branch %s does not exist\n", args[0])
+				os.Exit(1)
+			}
+
+			if len(args) == 1 {
+				env := b.Env()
+				keys := make([]string, 0, len(env))
+				for k := range env {
+					keys = append(keys, k)
+				}
+				sort.Strings(keys)
+				for _, k := range keys {
+					fmt.Printf("%s=%s\n", k, env[k])
+				}
+				return
+			}
+
+			for _, arg := range args[1:] {
+				key, value, ok := strings.Cut(arg, "=")
+				if !ok || !envKeyRegex.MatchString(key) {
+					fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m invalid %q, expected KEY=VALUE\n", arg)
+					os.Exit(1)
+				}
+				if err := b.SetEnv(key, value); err != nil {
+					fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m %v\n", err)
+					os.Exit(1)
+				}
+				if value == "" {
+					fmt.Printf("\033[0;32m✓\033[0m Unset %s\n", key)
+				} else {
+					fmt.Printf("\033[0;32m✓\033[0m Set %s\n", key)
+				}
+			}
+			if b.IsRunning() {
+				fmt.Println("  Restart the branch to apply")
+			}
+		},
+	}
+}
+
+// memoryLimitRegex matches docker --memory values like 512m or 4g.
+var memoryLimitRegex = regexp.MustCompile(`^[0-9]+[bkmgBKMG]?$`)
+
+func limitsCmd() *cobra.Command {
+	var cpus, memory string
+	var reset bool
+
+	cmd := &cobra.Command{
+		Use:               "limits <branch>",
+		Short:             "Show or set a branch's container CPU and memory limits",
+		Long:              "Show or set a branch's container CPU and memory limits.\n\nWithout flags, prints the current limits. Changes apply on next start.",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeBranchNames,
+		Run: func(cmd *cobra.Command, args []string) {
+			b := branch.New(args[0])
+			if !b.Exists() {
+				fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m branch %s does not exist\n", args[0])
+				os.Exit(1)
+			}
+
+			if cpus != "" {
+				if v, err := strconv.ParseFloat(cpus, 64); err != nil || v <= 0 {
+					fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m invalid --cpus %q\n", cpus)
+					os.Exit(1)
+				}
+			}
+			if memory != "" && !memoryLimitRegex.MatchString(memory) {
+				fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m invalid --memory %q (e.g. 4g, 512m)\n", memory)
+				os.Exit(1)
+			}
+
+			changed := false
+			for _, kv := range [][2]string{{"CPUS", cpus}, {"MEMORY", memory}} {
+				if kv[1] == "" && !reset {
+					continue
+				}
+				if err := b.SetMetadata(kv[0], kv[1]); err != nil {
+					fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m %v\n", err)
+					os.Exit(1)
+				}
+				changed = true
+			}
+
+			c, m := b.ResourceLimits()
+			if changed {
+				fmt.Printf("\033[0;32m✓\033[0m %s: --cpus %s --memory %s\n", b.Name, c, m)
+				if b.IsRunning() {
+					fmt.Println("  Restart the branch to apply")
+				}
+				return
+			}
+			fmt.Printf("%s: --cpus %s --memory %s\n", b.Name, c, m)
+		},
+	}
+
+	cmd.Flags().StringVar(&cpus, "cpus", "", "CPU limit (e.g. 2, 1.5)")
+	cmd.Flags().StringVar(&memory, "memory", "", "Memory limit (e.g. 4g, 512m)")
+	cmd.Flags().BoolVar(&reset, "reset", false, "Clear overrides and use the budget-derived defaults")
+
+	return cmd
+}
+
+func pushCmd() *cobra.Command {
+	var forceWithLease bool
+
+	cmd := &cobra.Command{
+		Use:               "push <name>",
+		Short:             "Push a branch to your GitHub fork",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeBranchNames,
+		Run: func(cmd *cobra.Command, args []string) {
+			name := args[0]
+			b := branch.New(name)
+
+			url, err := branch.Push(b, forceWithLease)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("\033[0;32m✓\033[0m Pushed %s\n", name)
+			if url != "" {
+				fmt.Printf("  Open a PR: %s\n", url)
+			}
+		},
+	}
+
+	cmd.Flags().BoolVar(&forceWithLease, "force-with-lease", false, "Force push, but only if the remote hasn't changed")
+	return cmd
+}
+
+func prCmd() *cobra.Command {
+	var title, body string
+
+	cmd := &cobra.Command{
+		Use:   "pr <name>",
+		Short: "Open a GitHub PR for a branch against darklang/dark main",
+		Long: `Open a GitHub PR for a branch using the gh CLI.
+
+The branch must already be pushed (see 'multi push'). Without --title,
+the title and body are filled from the branch's commits.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeBranchNames,
+		Run: func(cmd *cobra.Command, args []string) {
+			name := args[0]
+			b := branch.New(name)
+
+			if !b.Exists() {
+				fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m branch %s does not exist\n", name)
+				os.Exit(1)
+			}
+
+			url, err := branch.CreatePR(b, title, body)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("\033[0;32m✓\033[0m Created PR: %s\n", url)
+		},
+	}
+
+	cmd.Flags().StringVar(&title, "title", "", "PR title (default: filled from commits)")
+	cmd.Flags().StringVar(&body, "body", "", "PR body")
+	return cmd
+}
+
+func attachCmd() *cobra.Command {
+	var term bool
+
+	cmd := &cobra.Command{
+		Use:               "attach <name>",
+		Short:             "Attach this terminal to a branch's tmux session",
+		Long:              "Attach this terminal to a branch's Claude tmux session (or the terminal session with --term).",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeBranchNames,
+		Run: func(cmd *cobra.Command, args []string) {
+			sessionType := tmux.SessionClaude
+			if term {
+				sessionType = tmux.SessionTerminal
+			}
+
+			// Only returns on failure
+			if err := tmux.Attach(args[0], sessionType); err != nil {
+				fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().BoolVar(&term, "term", false, "Attach to the terminal session instead of Claude")
+	return cmd
+}
+
+func ctlCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "ctl <command> [name]",
+		Short: "Send a command to the running TUI",
+		Long: `Send a command to the running TUI over its control socket and print
+the JSON reply.
+
+Commands:
+  list           List branches
+  status <name>  Show a branch's state and URL
+  start <name>   Start a branch
+  stop <name>    Stop a branch`,
+		Args: cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			reply, err := control.Send(strings.Join(args, " "))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(reply)
+			if !strings.HasPrefix(reply, `{"ok":true`) {
+				os.Exit(1)
+			}
+		},
+	}
+}
+
+func doctorCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "doctor",
+		Short: "Check tools, config and whether branches can use the pre-built image",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			ok := func(format string, a ...any) { fmt.Printf("\033[0;32m✓\033[0m "+format+"\n", a...) }
+			warn := func(format string, a ...any) { fmt.Printf("\033[1;33m!\033[0m "+format+"\n", a...) }
+
+			for _, tool := range []string{"docker", "devcontainer", "tmux", "git"} {
+				if _, err := exec.LookPath(tool); err == nil {
+					ok("%s found", tool)
+				} else {
+					warn("%s not found on PATH", tool)
+				}
+			}
+			if config.GetGitHubFork() == "" {
+				warn("GitHub fork not configured (multi set-fork)")
+			} else {
+				ok("GitHub fork: %s", config.GetGitHubFork())
+			}
+			if _, running := proxy.IsRunning(); running {
+				ok("proxy running on port %d", config.GetProxyPort())
+			} else {
+				warn("proxy not running (multi proxy start)")
+			}
+
+			image := config.GetBaseImage()
+			fmt.Printf("\nPre-built image %s\n", image)
+			if container.ImagePresent(image) {
+				ok("pulled locally")
+			} else {
+				warn("not pulled yet (multi start <name> --pull-base)")
+			}
+			fmt.Printf("  expected Dockerfile hash %s\n", config.GetBaseDockerfileHash())
+			for _, b := range branch.GetManagedBranches() {
+				if !b.HasDevcontainer() {
+					continue
+				}
+				hash, err := container.DockerfileHash(b.Path)
+				switch {
+				case err != nil:
+					warn("%s: can't read Dockerfile - will build locally", b.Name)
+				case hash == config.GetBaseDockerfileHash():
+					ok("%s: uses pre-built image", b.Name)
+				default:
+					warn("%s: Dockerfile differs (%s) - will build locally", b.Name, hash)
+				}
+			}
+		},
+	}
+}
+
+func setBaseImageCmd() *cobra.Command {
+	var hash string
+	var fromBranch string
+	var reset bool
+
+	cmd := &cobra.Command{
+		Use:   "set-base-image [image]",
+		Short: "Set the pre-built base image branches start from",
+		Long: `Set the pre-built image used when a branch's Dockerfile matches the
+image's Dockerfile hash, e.g. a locally built base or a newer tag:
+  multi set-base-image darklang/dark-base:abc1234 --hash-from main
+  multi set-base-image my-dark-base:dev --hash <sha256 of its Dockerfile>
+  multi set-base-image --reset
+
+Without a hash, branches are compared against the built-in hash.
+Current setting can be viewed with:
+  multi set-base-image`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if reset {
+				if err := config.SetBaseImage("", ""); err != nil {
+					fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m %v\n", err)
+					os.Exit(1)
+				}
+				fmt.Printf("\033[0;32m✓\033[0m Base image reset to %s\n", config.DefaultBaseImage)
+				return
+			}
+			if len(args) == 0 {
+				fmt.Printf("Base image: %s\n", config.GetBaseImage())
+				fmt.Printf("Dockerfile hash: %s\n", config.GetBaseDockerfileHash())
+				return
+			}
+
+			if fromBranch != "" {
+				b := branch.New(fromBranch)
+				h, err := container.DockerfileHash(b.Path)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m can't read %s's Dockerfile: %v\n", fromBranch, err)
+					os.Exit(1)
+				}
+				hash = h
+			}
+
+			image := args[0]
+			if err := config.SetBaseImage(image, hash); err != nil {
+				fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("\033[0;32m✓\033[0m Base image set to: %s\n", image)
+			if hash != "" {
+				fmt.Printf("  Dockerfile hash: %s\n", hash)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&hash, "hash", "", "SHA256 of the Dockerfile the image was built from")
+	cmd.Flags().StringVar(&fromBranch, "hash-from", "", "Take the Dockerfile hash from this branch")
+	cmd.Flags().BoolVar(&reset, "reset", false, "Go back to the built-in base image")
+	return cmd
+}
+
+func setEditorCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set-editor <command>",
+		Short: "Set the editor the TUI opens branches in",
+		Long: `Set the editor command the TUI's 'e' opens branches in.
+
+Any VS Code-style editor that supports --remote attached-container works:
+  multi set-editor cursor
+  multi set-editor codium
+  multi set-editor code
+
+Current setting can be viewed with:
+  multi set-editor`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) == 0 {
+				fmt.Printf("Editor: %s\n", config.GetEditor())
+				return
+			}
+
+			editor := args[0]
+			if _, err := exec.LookPath(editor); err != nil {
+				fmt.Printf("\033[1;33m!\033[0m %s is not on your PATH\n", editor)
+			}
+			if err := config.SetEditor(editor); err != nil {
+				fmt.Fprintf(os.Stderr, "\033[0;31merror:\033[0m %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("\033[0;32m✓\033[0m Editor set to: %s\n", editor)
+		},
+	}
 }
 
 func setForkCmd() *cobra.Command {
@@ -305,3 +1501,32 @@ Current setting can be viewed with:
 		},
 	}
 }
+
+func configCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect dark-multi configuration",
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "show",
+		Short: "Show the effective configuration and where each value comes from",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			settings := config.EffectiveSettings()
+			width := 0
+			for _, s := range settings {
+				width = max(width, len(s.Name))
+			}
+			for _, s := range settings {
+				value := s.Value
+				if value == "" {
+					value = "\033[0;90m(unset)\033[0m"
+				}
+				fmt.Printf("%-*s %s \033[0;90m[%s]\033[0m\n", width, s.Name, value, s.Source)
+			}
+		},
+	})
+
+	return cmd
+}
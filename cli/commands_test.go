@@ -0,0 +1,26 @@
+package cli
+
+import "testing"
+
+func TestTailLines(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		n    int
+		want string
+	}{
+		{"fewer lines than n", "a\nb\n", 5, "a\nb\n"},
+		{"last n lines", "a\nb\nc\nd\n", 2, "c\nd\n"},
+		{"no trailing newline", "a\nb\nc", 2, "b\nc\n"},
+		{"empty", "", 3, ""},
+		{"zero means all", "a\nb\nc\n", 0, "a\nb\nc\n"},
+		{"negative means all", "a\nb\nc\n", -1, "a\nb\nc\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tailLines(tt.s, tt.n); got != tt.want {
+				t.Errorf("tailLines(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+			}
+		})
+	}
+}
@@ -2,11 +2,13 @@
 package config
 
 import (
+	"math"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 )
 
 var (
@@ -20,13 +22,37 @@ var (
 	OverridesDir = filepath.Join(ConfigDir, "overrides")
 	// TmuxSession is the tmux session name
 	TmuxSession = "dark"
-	// ProxyPort is the port for the URL proxy
-	ProxyPort = getEnvOrDefaultInt("DARK_MULTI_PROXY_PORT", 9000)
+	// ProxyTLSPort is the HTTPS port used by 'multi proxy start --tls'
+	ProxyTLSPort = getEnvOrDefaultInt("DARK_MULTI_PROXY_TLS_PORT", 9443)
 	// ProxyPIDFile stores the proxy process ID
 	ProxyPIDFile = filepath.Join(ConfigDir, "proxy.pid")
+	// LogFile is where multi logs its own debugging output
+	LogFile = getEnvOrDefault("DARK_MULTI_LOG_FILE", filepath.Join(ConfigDir, "multi.log"))
+	// LogLevel is the minimum level logged: debug, info, warn or error
+	LogLevel = getEnvOrDefault("DARK_MULTI_LOG_LEVEL", "info")
+	// ProxyLogFile is where 'multi proxy start --log' writes its access log (rotated like LogFile)
+	ProxyLogFile = filepath.Join(ConfigDir, "proxy.log")
 	// Terminal is the terminal emulator to use for tmux
 	// Options: gnome-terminal, kitty, alacritty, hyper, iterm2, terminal (macOS), auto
 	Terminal = getEnvOrDefault("DARK_MULTI_TERMINAL", "auto")
+	// AutoStartProxy starts the URL proxy when the TUI launches (also 'multi --no-proxy')
+	AutoStartProxy = getEnvOrDefaultBool("DARK_MULTI_AUTO_START_PROXY", true)
+	// StopProxyOnExit stops the URL proxy when the TUI quits
+	StopProxyOnExit = getEnvOrDefaultBool("DARK_MULTI_STOP_PROXY_ON_EXIT", false)
+	// PaneColors keeps Claude's colors in grid cells instead of plain text
+	PaneColors = getEnvOrDefaultBool("DARK_MULTI_PANE_COLORS", false)
+	// Notifications enables desktop notifications when Claude is waiting for input
+	Notifications = getEnvOrDefaultBool("DARK_MULTI_NOTIFICATIONS", false)
+	// InputTokenPrice is the estimated $ per million input tokens, for cost display
+	InputTokenPrice = getEnvOrDefaultFloat("DARK_MULTI_INPUT_PRICE", 3.0)
+	// OutputTokenPrice is the estimated $ per million output tokens, for cost display
+	OutputTokenPrice = getEnvOrDefaultFloat("DARK_MULTI_OUTPUT_PRICE", 15.0)
+	// MemoryHeadroomPct is the share of host RAM containers may use before the TUI
+	// refuses to start more. 0 disables the check.
+	MemoryHeadroomPct = getEnvOrDefaultInt("DARK_MULTI_MEMORY_HEADROOM_PCT", 0)
+	// MaxUptime stops containers running longer than this while the TUI is open, capping
+	// forgotten or runaway branches. 0 disables it.
+	MaxUptime = getEnvOrDefaultDuration("DARK_MULTI_MAX_UPTIME", 0)
 )
 
 const (
@@ -52,6 +78,33 @@ func getEnvOrDefaultInt(key string, defaultVal int) int {
 	return defaultVal
 }
 
+func getEnvOrDefaultBool(key string, defaultVal bool) bool {
+	if val := os.Getenv(key); val != "" {
+		if b, err := strconv.ParseBool(val); err == nil {
+			return b
+		}
+	}
+	return defaultVal
+}
+
+func getEnvOrDefaultFloat(key string, defaultVal float64) float64 {
+	if val := os.Getenv(key); val != "" {
+		if f, err := strconv.ParseFloat(val, 64); err == nil {
+			return f
+		}
+	}
+	return defaultVal
+}
+
+func getEnvOrDefaultDuration(key string, defaultVal time.Duration) time.Duration {
+	if val := os.Getenv(key); val != "" {
+		if d, err := time.ParseDuration(val); err == nil {
+			return d
+		}
+	}
+	return defaultVal
+}
+
 // GetSystemResources returns CPU cores and RAM in GB.
 func GetSystemResources() (cpuCores int, ramGB int) {
 	cpuCores = runtime.NumCPU()
@@ -86,6 +139,20 @@ func SuggestMaxInstances() int {
 	return min(ramLimit, cpuLimit, 10)
 }
 
+// DefaultContainerLimits returns the per-container CPU and memory limits,
+// splitting the host budget evenly across SuggestMaxInstances().
+// Budgets default to all cores and all RAM but 4GB.
+func DefaultContainerLimits() (cpus float64, memoryGB int) {
+	cpuCores, ramGB := GetSystemResources()
+	cpuBudget := getEnvOrDefaultFloat("DARK_MULTI_CPU_BUDGET", float64(cpuCores))
+	memBudget := getEnvOrDefaultInt("DARK_MULTI_MEMORY_BUDGET_GB", max(1, ramGB-4))
+
+	n := SuggestMaxInstances()
+	cpus = max(1, math.Floor(cpuBudget/float64(n)*10)/10)
+	memoryGB = max(2, memBudget/n)
+	return cpus, memoryGB
+}
+
 // GetAnthropicAPIKey returns the Anthropic API key from env or config file.
 func GetAnthropicAPIKey() string {
 	// Check environment first
@@ -124,3 +191,196 @@ func SetGitHubFork(url string) error {
 	forkFile := filepath.Join(ConfigDir, "github-fork")
 	return os.WriteFile(forkFile, []byte(url+"\n"), 0600)
 }
+
+// Pre-built image configuration.
+// When a branch's Dockerfile matches the expected hash, the pre-built image is
+// used instead of rebuilding, which saves significant startup time.
+const (
+	// DefaultBaseImage is the pre-built image on Docker Hub
+	DefaultBaseImage = "darklang/dark-base:7dc786d"
+	// DefaultBaseDockerfileHash is the SHA256 of the Dockerfile DefaultBaseImage was built from
+	DefaultBaseDockerfileHash = "83d9d227c58ffdcdb35cb1bfade4626d947007112cc1b4d59223f0031eca4fb2"
+)
+
+// GetBaseImage returns the pre-built base image, e.g. a locally built tag.
+func GetBaseImage() string {
+	// Check environment first
+	if image := os.Getenv("DARK_MULTI_BASE_IMAGE"); image != "" {
+		return image
+	}
+
+	// Check config file
+	imageFile := filepath.Join(ConfigDir, "base-image")
+	if data, err := os.ReadFile(imageFile); err == nil {
+		if image := strings.TrimSpace(string(data)); image != "" {
+			return image
+		}
+	}
+
+	return DefaultBaseImage
+}
+
+// GetBaseDockerfileHash returns the Dockerfile hash the base image was built
+// from; branches whose Dockerfile matches use the base image.
+func GetBaseDockerfileHash() string {
+	// Check environment first
+	if hash := os.Getenv("DARK_MULTI_BASE_IMAGE_HASH"); hash != "" {
+		return hash
+	}
+
+	// Check config file
+	hashFile := filepath.Join(ConfigDir, "base-image-hash")
+	if data, err := os.ReadFile(hashFile); err == nil {
+		if hash := strings.TrimSpace(string(data)); hash != "" {
+			return hash
+		}
+	}
+
+	return DefaultBaseDockerfileHash
+}
+
+// SetBaseImage saves the base image and the Dockerfile hash it was built
+// from. An empty image restores the defaults.
+func SetBaseImage(image, hash string) error {
+	os.MkdirAll(ConfigDir, 0755)
+	imageFile := filepath.Join(ConfigDir, "base-image")
+	hashFile := filepath.Join(ConfigDir, "base-image-hash")
+	if image == "" {
+		os.Remove(imageFile)
+		os.Remove(hashFile)
+		return nil
+	}
+	if err := os.WriteFile(imageFile, []byte(image+"\n"), 0644); err != nil {
+		return err
+	}
+	if hash == "" {
+		os.Remove(hashFile)
+		return nil
+	}
+	return os.WriteFile(hashFile, []byte(hash+"\n"), 0644)
+}
+
+// DefaultEditor is the editor command used when none is configured.
+const DefaultEditor = "code"
+
+// GetEditor returns the command used to open a branch in an editor, e.g.
+// "code", "cursor" or "codium".
+func GetEditor() string {
+	// Check environment first
+	if editor := os.Getenv("DARK_MULTI_EDITOR"); editor != "" {
+		return editor
+	}
+
+	// Check config file
+	editorFile := filepath.Join(ConfigDir, "editor")
+	if data, err := os.ReadFile(editorFile); err == nil {
+		if editor := strings.TrimSpace(string(data)); editor != "" {
+			return editor
+		}
+	}
+
+	return DefaultEditor
+}
+
+// SetEditor saves the editor command to config.
+func SetEditor(editor string) error {
+	os.MkdirAll(ConfigDir, 0755)
+	editorFile := filepath.Join(ConfigDir, "editor")
+	return os.WriteFile(editorFile, []byte(editor+"\n"), 0644)
+}
+
+// DefaultGridRefreshInterval is how often the grid captures panes and docker stats.
+const DefaultGridRefreshInterval = 1 * time.Second
+
+// GetGridRefreshInterval returns how often the grid runs its expensive loads
+// (tmux capture-pane per branch, docker stats). Accepts a duration ("3s") or
+// whole seconds; anything under a second is rounded up to the grid's tick.
+func GetGridRefreshInterval() time.Duration {
+	// Check environment first, then the config file, skipping unparseable values
+	if d, ok := parseRefreshInterval(os.Getenv("DARK_MULTI_REFRESH_INTERVAL")); ok {
+		return d
+	}
+	if data, err := os.ReadFile(filepath.Join(ConfigDir, "refresh-interval")); err == nil {
+		if d, ok := parseRefreshInterval(strings.TrimSpace(string(data))); ok {
+			return d
+		}
+	}
+	return DefaultGridRefreshInterval
+}
+
+// parseRefreshInterval parses a duration or whole seconds, raised to at
+// least DefaultGridRefreshInterval.
+func parseRefreshInterval(val string) (time.Duration, bool) {
+	d, err := time.ParseDuration(val)
+	if err != nil {
+		secs, err := strconv.Atoi(val)
+		if err != nil {
+			return 0, false
+		}
+		d = time.Duration(secs) * time.Second
+	}
+	return max(d, DefaultGridRefreshInterval), true
+}
+
+// DefaultProxyPort is the URL proxy port when none is configured.
+const DefaultProxyPort = 9000
+
+// GetProxyPort returns the port for the URL proxy.
+func GetProxyPort() int {
+	// Check environment first
+	if val := os.Getenv("DARK_MULTI_PROXY_PORT"); val != "" {
+		if port, err := strconv.Atoi(val); err == nil {
+			return port
+		}
+	}
+
+	// Check config file
+	portFile := filepath.Join(ConfigDir, "proxy-port")
+	if data, err := os.ReadFile(portFile); err == nil {
+		if port, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil {
+			return port
+		}
+	}
+
+	return DefaultProxyPort
+}
+
+// SetProxyPort saves the URL proxy port to config.
+func SetProxyPort(port int) error {
+	os.MkdirAll(ConfigDir, 0755)
+	portFile := filepath.Join(ConfigDir, "proxy-port")
+	return os.WriteFile(portFile, []byte(strconv.Itoa(port)+"\n"), 0644)
+}
+
+// GetDockerImage returns the image used for branches without a devcontainer.json.
+func GetDockerImage() string {
+	// Check environment first
+	if image := os.Getenv("DARK_MULTI_DOCKER_IMAGE"); image != "" {
+		return image
+	}
+
+	// Check config file
+	imageFile := filepath.Join(ConfigDir, "docker-image")
+	if data, err := os.ReadFile(imageFile); err == nil {
+		return strings.TrimSpace(string(data))
+	}
+
+	return ""
+}
+
+// GetDockerCommand returns the command run in plain Docker image containers.
+// Defaults to keeping the container alive so tmux sessions can exec into it.
+func GetDockerCommand() string {
+	if cmd := os.Getenv("DARK_MULTI_DOCKER_CMD"); cmd != "" {
+		return cmd
+	}
+
+	cmdFile := filepath.Join(ConfigDir, "docker-cmd")
+	if data, err := os.ReadFile(cmdFile); err == nil {
+		if cmd := strings.TrimSpace(string(data)); cmd != "" {
+			return cmd
+		}
+	}
+
+	return "sleep infinity"
+}
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Keybinding actions, as used in keys.json.
+const (
+	KeyQuit         = "quit"
+	KeyLeft         = "left"
+	KeyRight        = "right"
+	KeyUp           = "up"
+	KeyDown         = "down"
+	KeyClaude       = "claude"
+	KeyTerminal     = "terminal"
+	KeyStart        = "start"
+	KeyStartAll     = "startAll"
+	KeyKill         = "kill"
+	KeyKillAll      = "killAll"
+	KeyNewBranch    = "newBranch"
+	KeyDelete       = "delete"
+	KeyEditor       = "editor"
+	KeyMatter       = "matter"
+	KeyCopyURL      = "copyURL"
+	KeyCopyID       = "copyID"
+	KeyDiff         = "diff"
+	KeyDiffExternal = "diffExternal"
+	KeyLogs         = "logs"
+	KeyDiskUsage    = "diskUsage"
+	KeySort         = "sort"
+	KeyListMode     = "listMode"
+	KeySearch       = "search"
+	KeyGrid         = "grid"
+	KeyHelp         = "help"
+)
+
+// Keybindings maps TUI actions to the keys that trigger them, and each key
+// back to exactly one action.
+type Keybindings struct {
+	keys    map[string][]string // action -> keys
+	actions map[string]string   // key -> action
+	custom  map[string]bool     // actions bound by keys.json
+
+	// Warnings describes problems in keys.json: unknown actions, keys bound
+	// to more than one action, or a file that doesn't parse.
+	Warnings []string
+}
+
+// defaultKeys returns the built-in action -> keys bindings.
+func defaultKeys() map[string][]string {
+	return map[string][]string{
+		KeyQuit:         {"q", "ctrl+c"},
+		KeyLeft:         {"left"},
+		KeyRight:        {"right"},
+		KeyUp:           {"up"},
+		KeyDown:         {"down"},
+		KeyClaude:       {"c", "enter"},
+		KeyTerminal:     {"t"},
+		KeyStart:        {"s"},
+		KeyStartAll:     {"S"},
+		KeyKill:         {"k"},
+		KeyKillAll:      {"K"},
+		KeyNewBranch:    {"n"},
+		KeyDelete:       {"x"},
+		KeyEditor:       {"e"},
+		KeyMatter:       {"m"},
+		KeyCopyURL:      {"y"},
+		KeyCopyID:       {"Y"},
+		KeyDiff:         {"d"},
+		KeyDiffExternal: {"D"},
+		KeyLogs:         {"l"},
+		KeyDiskUsage:    {"u"},
+		KeySort:         {"o"},
+		KeyListMode:     {"L"},
+		KeySearch:       {"/"},
+		KeyGrid:         {"g"},
+		KeyHelp:         {"?"},
+	}
+}
+
+// DefaultKeybindings returns the built-in keybindings.
+func DefaultKeybindings() Keybindings {
+	return resolveKeybindings(defaultKeys(), nil)
+}
+
+// LoadKeybindings returns the defaults with any actions from keys.json
+// in ConfigDir replacing them, e.g. {"kill": ["x"], "delete": ["X"]}.
+func LoadKeybindings() Keybindings {
+	data, err := os.ReadFile(filepath.Join(ConfigDir, "keys.json"))
+	if err != nil {
+		return DefaultKeybindings()
+	}
+	var custom map[string][]string
+	if err := json.Unmarshal(data, &custom); err != nil {
+		kb := DefaultKeybindings()
+		kb.Warnings = append(kb.Warnings, fmt.Sprintf("keys.json: %v", err))
+		return kb
+	}
+	return resolveKeybindings(defaultKeys(), custom)
+}
+
+// resolveKeybindings layers custom bindings over the defaults. A custom
+// binding takes its keys away from whatever default action had them, so
+// {"kill": ["x"]} leaves delete unbound rather than sharing x. When two
+// custom actions claim the same key, the first by action name wins and a
+// warning is recorded.
+func resolveKeybindings(defaults, custom map[string][]string) Keybindings {
+	kb := Keybindings{keys: make(map[string][]string), actions: make(map[string]string), custom: make(map[string]bool)}
+	bind := func(action, key string) {
+		kb.actions[key] = action
+		kb.keys[action] = append(kb.keys[action], key)
+	}
+
+	for _, action := range sortedActions(custom) {
+		if _, ok := defaults[action]; !ok {
+			kb.Warnings = append(kb.Warnings, fmt.Sprintf("keys.json: unknown action %q", action))
+			continue
+		}
+		kb.custom[action] = true
+		for _, key := range custom[action] {
+			if other, taken := kb.actions[key]; taken {
+				kb.Warnings = append(kb.Warnings, fmt.Sprintf("keys.json: %q is bound to both %s and %s; using %s", key, other, action, other))
+				continue
+			}
+			bind(action, key)
+		}
+	}
+
+	for _, action := range sortedActions(defaults) {
+		if _, overridden := custom[action]; overridden {
+			continue
+		}
+		for _, key := range defaults[action] {
+			if _, taken := kb.actions[key]; !taken {
+				bind(action, key)
+			}
+		}
+	}
+	return kb
+}
+
+func sortedActions(bindings map[string][]string) []string {
+	actions := make([]string, 0, len(bindings))
+	for action := range bindings {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	return actions
+}
+
+// Action returns the action bound to key, or "" if none is.
+func (kb Keybindings) Action(key string) string {
+	return kb.actions[key]
+}
+
+// Keys returns the keys bound to an action, for display.
+func (kb Keybindings) Keys(action string) []string {
+	return kb.keys[action]
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestKeybindingsAction(t *testing.T) {
+	defaults := map[string][]string{
+		KeyKill:   {"k"},
+		KeyDelete: {"x"},
+		KeyStart:  {"s"},
+		KeyClaude: {"c", "enter"},
+	}
+	tests := []struct {
+		name     string
+		custom   map[string][]string
+		key      string
+		want     string
+		warnings int
+	}{
+		{"default", nil, "k", KeyKill, 0},
+		{"second default key", nil, "enter", KeyClaude, 0},
+		{"unbound", nil, "z", "", 0},
+		{"remapped key", map[string][]string{KeyKill: {"x"}}, "x", KeyKill, 0},
+		{"old key of remapped action", map[string][]string{KeyKill: {"x"}}, "k", "", 0},
+		{"swap", map[string][]string{KeyKill: {"x"}, KeyDelete: {"k"}}, "k", KeyDelete, 0},
+		// Conflicts between custom actions go to the first by name, with a warning
+		{"custom conflict", map[string][]string{KeyStart: {"x"}, KeyKill: {"x"}}, "x", KeyKill, 1},
+		{"unknown action", map[string][]string{"bogus": {"b"}}, "b", "", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Resolve repeatedly: map iteration order must not change the result
+			for i := 0; i < 20; i++ {
+				kb := resolveKeybindings(defaults, tt.custom)
+				if got := kb.Action(tt.key); got != tt.want {
+					t.Fatalf("Action(%q) = %q, want %q", tt.key, got, tt.want)
+				}
+				if len(kb.Warnings) != tt.warnings {
+					t.Fatalf("got warnings %q, want %d", kb.Warnings, tt.warnings)
+				}
+			}
+		})
+	}
+}
+
+func TestKeybindingsKeysDropsTakenDefaults(t *testing.T) {
+	defaults := map[string][]string{KeyKill: {"k"}, KeyDelete: {"x", "X"}}
+	kb := resolveKeybindings(defaults, map[string][]string{KeyKill: {"x"}})
+	if got := kb.Keys(KeyDelete); !reflect.DeepEqual(got, []string{"X"}) {
+		t.Errorf("Keys(delete) = %q, want [X]", got)
+	}
+}
+
+func TestDefaultKeybindingsUnique(t *testing.T) {
+	seen := make(map[string]string)
+	for action, keys := range defaultKeys() {
+		for _, k := range keys {
+			if other, ok := seen[k]; ok {
+				t.Errorf("key %q bound to both %s and %s", k, other, action)
+			}
+			seen[k] = action
+		}
+	}
+}
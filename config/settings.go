@@ -0,0 +1,126 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Setting is a resolved configuration value and where it came from.
+type Setting struct {
+	Name   string
+	Value  string
+	Source string // "default", "env", or "file"
+}
+
+// EffectiveSettings returns every resolved setting with its source.
+func EffectiveSettings() []Setting {
+	apiKey := GetAnthropicAPIKey()
+	if len(apiKey) > 8 {
+		apiKey = apiKey[:4] + "…" + apiKey[len(apiKey)-4:]
+	} else if apiKey != "" {
+		apiKey = "(set)"
+	}
+
+	// The budgets are read where limits are computed; show what's in effect
+	cpuBudget, cpuSource := "all cores", envSource("DARK_MULTI_CPU_BUDGET", isFloat)
+	if cpuSource == "env" {
+		cpuBudget = os.Getenv("DARK_MULTI_CPU_BUDGET")
+	}
+	memBudget, memSource := "RAM - 4", envSource("DARK_MULTI_MEMORY_BUDGET_GB", isInt)
+	if memSource == "env" {
+		memBudget = os.Getenv("DARK_MULTI_MEMORY_BUDGET_GB")
+	}
+
+	settings := []Setting{
+		{"DarkRoot", DarkRoot, envSource("DARK_ROOT", nil)},
+		{"DarkSource", DarkSource, envSource("DARK_SOURCE", nil)},
+		{"LogFile", LogFile, envSource("DARK_MULTI_LOG_FILE", nil)},
+		{"LogLevel", LogLevel, envSource("DARK_MULTI_LOG_LEVEL", nil)},
+		{"ConfigDir", ConfigDir, envSource("DARK_MULTI_CONFIG", nil)},
+		{"ProxyPort", strconv.Itoa(GetProxyPort()), envOrFileSource("DARK_MULTI_PROXY_PORT", "proxy-port", isInt)},
+		{"GridRefreshInterval", GetGridRefreshInterval().String(), envOrFileSource("DARK_MULTI_REFRESH_INTERVAL", "refresh-interval", isRefreshInterval)},
+		{"ProxyTLSPort", strconv.Itoa(ProxyTLSPort), envSource("DARK_MULTI_PROXY_TLS_PORT", isInt)},
+		{"Terminal", Terminal, envSource("DARK_MULTI_TERMINAL", nil)},
+		{"Notifications", strconv.FormatBool(Notifications), envSource("DARK_MULTI_NOTIFICATIONS", isBool)},
+		{"PaneColors", strconv.FormatBool(PaneColors), envSource("DARK_MULTI_PANE_COLORS", isBool)},
+		{"AutoStartProxy", strconv.FormatBool(AutoStartProxy), envSource("DARK_MULTI_AUTO_START_PROXY", isBool)},
+		{"StopProxyOnExit", strconv.FormatBool(StopProxyOnExit), envSource("DARK_MULTI_STOP_PROXY_ON_EXIT", isBool)},
+		{"MemoryHeadroomPct", strconv.Itoa(MemoryHeadroomPct), envSource("DARK_MULTI_MEMORY_HEADROOM_PCT", isInt)},
+		{"MaxUptime", MaxUptime.String(), envSource("DARK_MULTI_MAX_UPTIME", isDuration)},
+		{"InputTokenPrice", strconv.FormatFloat(InputTokenPrice, 'f', -1, 64), envSource("DARK_MULTI_INPUT_PRICE", isFloat)},
+		{"OutputTokenPrice", strconv.FormatFloat(OutputTokenPrice, 'f', -1, 64), envSource("DARK_MULTI_OUTPUT_PRICE", isFloat)},
+		{"CPUBudget", cpuBudget, cpuSource},
+		{"MemoryBudgetGB", memBudget, memSource},
+		{"GitHubFork", GetGitHubFork(), envOrFileSource("DARK_GITHUB_FORK", "github-fork", nil)},
+		{"Editor", GetEditor(), envOrFileSource("DARK_MULTI_EDITOR", "editor", nil)},
+		{"BaseImage", GetBaseImage(), envOrFileSource("DARK_MULTI_BASE_IMAGE", "base-image", nil)},
+		{"BaseImageHash", GetBaseDockerfileHash(), envOrFileSource("DARK_MULTI_BASE_IMAGE_HASH", "base-image-hash", nil)},
+		{"AnthropicAPIKey", apiKey, envOrFileSource("ANTHROPIC_API_KEY", "anthropic-api-key", nil)},
+		{"DockerImage", GetDockerImage(), envOrFileSource("DARK_MULTI_DOCKER_IMAGE", "docker-image", nil)},
+		{"DockerCommand", GetDockerCommand(), envOrFileSource("DARK_MULTI_DOCKER_CMD", "docker-cmd", nil)},
+	}
+
+	kb := LoadKeybindings()
+	for _, action := range sortedActions(defaultKeys()) {
+		source := "default"
+		if kb.custom[action] {
+			source = "file"
+		}
+		settings = append(settings, Setting{"Key." + action, strings.Join(kb.Keys(action), " "), source})
+	}
+	return settings
+}
+
+// envSource reports whether a value came from the environment or the
+// default. A set variable that valid rejects was ignored, so it counts as
+// the default; a nil valid accepts any non-empty value.
+func envSource(key string, valid func(string) bool) string {
+	if val := os.Getenv(key); val != "" && (valid == nil || valid(val)) {
+		return "env"
+	}
+	return "default"
+}
+
+// envOrFileSource reports whether a value came from the environment, a file
+// in ConfigDir, or the default, skipping values valid rejects like the
+// getters do.
+func envOrFileSource(key, file string, valid func(string) bool) string {
+	if envSource(key, valid) == "env" {
+		return "env"
+	}
+	if data, err := os.ReadFile(filepath.Join(ConfigDir, file)); err == nil {
+		if val := strings.TrimSpace(string(data)); val != "" && (valid == nil || valid(val)) {
+			return "file"
+		}
+	}
+	return "default"
+}
+
+func isInt(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
+
+func isBool(s string) bool {
+	_, err := strconv.ParseBool(s)
+	return err == nil
+}
+
+func isFloat(s string) bool {
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
+
+func isDuration(s string) bool {
+	_, err := time.ParseDuration(s)
+	return err == nil
+}
+
+// isRefreshInterval accepts what GetGridRefreshInterval does: a duration or
+// whole seconds.
+func isRefreshInterval(s string) bool {
+	return isDuration(s) || isInt(s)
+}
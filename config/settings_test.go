@@ -0,0 +1,42 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnvOrFileSource(t *testing.T) {
+	saved := ConfigDir
+	ConfigDir = t.TempDir()
+	t.Cleanup(func() { ConfigDir = saved })
+
+	tests := []struct {
+		name string
+		env  string
+		file string
+		want string
+	}{
+		{"unset", "", "", "default"},
+		{"env", "9100", "", "env"},
+		{"file", "", "9200", "file"},
+		{"env beats file", "9100", "9200", "env"},
+		{"bad env falls back to file", "abc", "9200", "file"},
+		{"bad env and file", "abc", "xyz", "default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DARK_MULTI_TEST_PORT", tt.env)
+			path := filepath.Join(ConfigDir, "test-port")
+			os.Remove(path)
+			if tt.file != "" {
+				if err := os.WriteFile(path, []byte(tt.file+"\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := envOrFileSource("DARK_MULTI_TEST_PORT", "test-port", isInt); got != tt.want {
+				t.Errorf("envOrFileSource = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -6,34 +6,18 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/darklang/dark-multi/config"
+	"github.com/darklang/dark-multi/log"
 )
 
-// Pre-built image configuration.
-// When the local Dockerfile matches this hash, we use the pre-built image
-// instead of rebuilding, which saves significant startup time.
-const (
-	// SHA256 hash of the Dockerfile used to build the base image
-	baseDockerfileHash = "83d9d227c58ffdcdb35cb1bfade4626d947007112cc1b4d59223f0031eca4fb2"
-	// Pre-built image on Docker Hub
-	baseImage = "darklang/dark-base:7dc786d"
-)
-
-// logToFile writes debug output to /tmp/dark-multi.log
-func logToFile(format string, args ...interface{}) {
-	f, err := os.OpenFile("/tmp/dark-multi.log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return
-	}
-	defer f.Close()
-	msg := fmt.Sprintf(format, args...)
-	f.WriteString(fmt.Sprintf("[devcontainer] %s\n", msg))
-}
+var logger = log.New("container")
 
 // getGitConfig returns a git config value from the host.
 func getGitConfig(key string) string {
@@ -50,17 +34,104 @@ type BranchInfo interface {
 	GetPath() string
 	PortBase() int
 	BwdPortBase() int
+	ResourceLimits() (cpus, memory string)
+	Env() map[string]string
+}
+
+// portRunArgs returns the docker -p arguments mapping a branch's host ports
+// to the fixed container ports.
+func portRunArgs(b BranchInfo) []string {
+	var portArgs []string
+	// BwdServer ports
+	portArgs = append(portArgs, "-p", fmt.Sprintf("%d:11001", b.BwdPortBase()))
+	portArgs = append(portArgs, "-p", fmt.Sprintf("%d:11002", b.BwdPortBase()+1))
+	// Test server ports (10011-10030)
+	for i := 0; i < 20; i++ {
+		portArgs = append(portArgs, "-p", fmt.Sprintf("%d:%d", b.PortBase()+i, 10011+i))
+	}
+	return portArgs
+}
+
+// HostPorts returns the host ports a branch's container maps.
+func HostPorts(b BranchInfo) []int {
+	ports := []int{b.BwdPortBase(), b.BwdPortBase() + 1}
+	for i := 0; i < 20; i++ {
+		ports = append(ports, b.PortBase()+i)
+	}
+	return ports
+}
+
+// CheckPortsFree probes each of a branch's host ports and returns an error
+// listing any that are already taken, since docker's own failure is opaque.
+func CheckPortsFree(b BranchInfo) error {
+	var taken []string
+	for _, port := range HostPorts(b) {
+		ln, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+		if err != nil {
+			taken = append(taken, strconv.Itoa(port))
+			continue
+		}
+		ln.Close()
+	}
+	if len(taken) > 0 {
+		return fmt.Errorf("ports already in use for %s: %s (check with 'lsof -i :%s')",
+			b.GetName(), strings.Join(taken, ", "), taken[0])
+	}
+	return nil
+}
+
+// limitRunArgs returns the docker --cpus/--memory args for a branch.
+func limitRunArgs(b BranchInfo) []string {
+	cpus, memory := b.ResourceLimits()
+	return []string{"--cpus", cpus, "--memory", memory}
+}
+
+// identityRunArgs returns the hostname/label/name args that identify a branch container.
+func identityRunArgs(name string) []string {
+	return []string{
+		"--hostname", fmt.Sprintf("dark-%s", name),
+		"--label", fmt.Sprintf("dark-dev-container=%s", name),
+		"--name", fmt.Sprintf("dark-%s", name),
+	}
+}
+
+// branchMounts returns the branch-specific volumes and shared Claude mounts.
+func branchMounts(name string) []string {
+	homeDir, _ := os.UserHomeDir()
+	claudeDir := filepath.Join(homeDir, ".claude")
+	claudeJson := filepath.Join(homeDir, ".claude.json")
+
+	// Note: We intentionally do NOT mount ~/.ssh or ~/.gitconfig to avoid leaking credentials.
+	// Git identity (user.name/user.email) is set via postCreateCommand.
+	return []string{
+		fmt.Sprintf("type=volume,src=%s%s,dst=/home/dark/.nuget", nugetVolumePrefix, name),
+		fmt.Sprintf("type=volume,src=%s%s,dst=/home/dark/.vscode-server/extensions", vscodeExtVolumePrefix, name),
+		fmt.Sprintf("type=volume,src=%s%s,dst=/home/dark/.vscode-server-insiders/extensions", vscodeInsidersVolumePrefix, name),
+		// Mount Claude credentials and config (shared across branches)
+		fmt.Sprintf("type=bind,src=%s,dst=/home/dark/.claude,consistency=cached", claudeDir),
+		// Mount .claude.json for auth/theme (writable - Claude needs to save settings)
+		fmt.Sprintf("type=bind,src=%s,dst=/home/dark/.claude.json", claudeJson),
+	}
 }
 
 // GetOverrideConfigPath returns the path to the override config for a branch.
 func GetOverrideConfigPath(name string) string {
-	return filepath.Join(config.ConfigDir, "overrides", name, "devcontainer.json")
+	return filepath.Join(config.OverridesDir, name, "devcontainer.json")
 }
 
 // dockerfileMatchesBase checks if the Dockerfile in the branch matches
 // the hash of the Dockerfile used to build the pre-built base image.
 func dockerfileMatchesBase(branchPath string) bool {
-	// Read the Dockerfile - it may be in root or .devcontainer
+	hash, err := DockerfileHash(branchPath)
+	if err != nil {
+		return false // Can't read, fall back to build
+	}
+	return hash == config.GetBaseDockerfileHash()
+}
+
+// DockerfileHash returns the SHA256 of a branch's Dockerfile, which may be
+// in the repo root or .devcontainer.
+func DockerfileHash(branchPath string) (string, error) {
 	dockerfilePath := filepath.Join(branchPath, "Dockerfile")
 	if _, err := os.Stat(dockerfilePath); os.IsNotExist(err) {
 		dockerfilePath = filepath.Join(branchPath, ".devcontainer", "Dockerfile")
@@ -68,12 +139,67 @@ func dockerfileMatchesBase(branchPath string) bool {
 
 	content, err := os.ReadFile(dockerfilePath)
 	if err != nil {
-		return false // Can't read, fall back to build
+		return "", err
 	}
 
 	hash := sha256.Sum256(content)
-	hexHash := hex.EncodeToString(hash[:])
-	return hexHash == baseDockerfileHash
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// PullBaseImage pulls the pre-built base image, streaming docker's output.
+func PullBaseImage() error {
+	image := config.GetBaseImage()
+	cmd := exec.Command("docker", "pull", image)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to pull %s: %w", image, err)
+	}
+	return nil
+}
+
+// ImagePresent reports whether an image exists locally.
+func ImagePresent(image string) bool {
+	return exec.Command("docker", "image", "inspect", image).Run() == nil
+}
+
+// PrebuiltImage returns the pre-built base image and whether a branch's
+// Dockerfile matches it, i.e. whether starting will skip the local build.
+func PrebuiltImage(branchPath string) (string, bool) {
+	return config.GetBaseImage(), dockerfileMatchesBase(branchPath)
+}
+
+// PrebuiltMismatch explains why a branch can't use the pre-built image, or
+// returns "" if it can.
+func PrebuiltMismatch(branchPath string) string {
+	hash, err := DockerfileHash(branchPath)
+	if err != nil {
+		return "can't read Dockerfile"
+	}
+	expected := config.GetBaseDockerfileHash()
+	if hash == expected {
+		return ""
+	}
+	return fmt.Sprintf("Dockerfile differs from base (got %.7s, want %.7s)", hash, expected)
+}
+
+// BaseImageHashLabel is the image label recording the SHA256 of the
+// Dockerfile a base image was built from.
+const BaseImageHashLabel = "org.darklang.dockerfile-hash"
+
+// ImageDockerfileHash returns the Dockerfile hash a local image was labelled
+// with, or "" if it has none.
+func ImageDockerfileHash(image string) string {
+	format := fmt.Sprintf("{{index .Config.Labels %q}}", BaseImageHashLabel)
+	out, err := exec.Command("docker", "image", "inspect", "--format", format, image).Output()
+	if err != nil {
+		return ""
+	}
+	hash := strings.TrimSpace(string(out))
+	if hash == "<no value>" {
+		return ""
+	}
+	return hash
 }
 
 // GenerateOverrideConfig generates a devcontainer override config for a branch.
@@ -82,11 +208,10 @@ func GenerateOverrideConfig(b BranchInfo) (string, error) {
 	name := b.GetName()
 	branchPath := b.GetPath()
 
-	overrideDir := filepath.Join(config.ConfigDir, "overrides", name)
-	if err := os.MkdirAll(overrideDir, 0755); err != nil {
+	overridePath := GetOverrideConfigPath(name)
+	if err := os.MkdirAll(filepath.Dir(overridePath), 0755); err != nil {
 		return "", fmt.Errorf("failed to create override dir: %w", err)
 	}
-	overridePath := filepath.Join(overrideDir, "devcontainer.json")
 
 	// Read original devcontainer.json
 	originalPath := filepath.Join(branchPath, ".devcontainer", "devcontainer.json")
@@ -120,15 +245,7 @@ func GenerateOverrideConfig(b BranchInfo) (string, error) {
 		return "", fmt.Errorf("failed to parse devcontainer.json: %w", err)
 	}
 
-	// Build port mappings
-	var portArgs []string
-	// BwdServer ports
-	portArgs = append(portArgs, "-p", fmt.Sprintf("%d:11001", b.BwdPortBase()))
-	portArgs = append(portArgs, "-p", fmt.Sprintf("%d:11002", b.BwdPortBase()+1))
-	// Test server ports (10011-10030)
-	for i := 0; i < 20; i++ {
-		portArgs = append(portArgs, "-p", fmt.Sprintf("%d:%d", b.PortBase()+i, 10011+i))
-	}
+	portArgs := portRunArgs(b)
 
 	// Host ports for forwardPorts
 	var hostPorts []interface{}
@@ -145,13 +262,13 @@ func GenerateOverrideConfig(b BranchInfo) (string, error) {
 	if dockerfileMatchesBase(branchPath) {
 		// Remove build section and use pre-built image
 		delete(cfg, "build")
-		cfg["image"] = baseImage
-		logToFile("Using pre-built image: %s", baseImage)
+		cfg["image"] = config.GetBaseImage()
+		logger.Infof("Using pre-built image: %s", cfg["image"])
 	} else {
-		logToFile("Dockerfile differs from base - will build locally")
+		logger.Warnf("Not using pre-built image %s: %s - will build locally", config.GetBaseImage(), PrebuiltMismatch(branchPath))
 	}
 
-	// Merge runArgs - filter out existing hostname/label/name/-p args
+	// Merge runArgs - filter out existing hostname/label/name/-p/limit args
 	var filteredArgs []string
 	if originalArgs, ok := cfg["runArgs"].([]interface{}); ok {
 		skipNext := false
@@ -164,12 +281,14 @@ func GenerateOverrideConfig(b BranchInfo) (string, error) {
 				skipNext = false
 				continue
 			}
-			if argStr == "--hostname" || argStr == "--label" || argStr == "--name" || argStr == "-p" {
+			if argStr == "--hostname" || argStr == "--label" || argStr == "--name" || argStr == "-p" ||
+				argStr == "--cpus" || argStr == "--memory" {
 				skipNext = true
 				continue
 			}
 			if strings.HasPrefix(argStr, "--hostname=") || strings.HasPrefix(argStr, "--label=") ||
-				strings.HasPrefix(argStr, "--name=") || strings.HasPrefix(argStr, "-p=") {
+				strings.HasPrefix(argStr, "--name=") || strings.HasPrefix(argStr, "-p=") ||
+				strings.HasPrefix(argStr, "--cpus=") || strings.HasPrefix(argStr, "--memory=") {
 				continue
 			}
 			filteredArgs = append(filteredArgs, argStr)
@@ -181,33 +300,22 @@ func GenerateOverrideConfig(b BranchInfo) (string, error) {
 	for _, arg := range filteredArgs {
 		newRunArgs = append(newRunArgs, arg)
 	}
-	newRunArgs = append(newRunArgs,
-		"--hostname", fmt.Sprintf("dark-%s", name),
-		"--label", fmt.Sprintf("dark-dev-container=%s", name),
-		"--name", fmt.Sprintf("dark-%s", name),
-	)
+	for _, arg := range identityRunArgs(name) {
+		newRunArgs = append(newRunArgs, arg)
+	}
 	for _, arg := range portArgs {
 		newRunArgs = append(newRunArgs, arg)
 	}
+	for _, arg := range limitRunArgs(b) {
+		newRunArgs = append(newRunArgs, arg)
+	}
 	cfg["runArgs"] = newRunArgs
 
 	// Override mounts with branch-specific volumes
-	homeDir, _ := os.UserHomeDir()
-	claudeDir := filepath.Join(homeDir, ".claude")
-	claudeJson := filepath.Join(homeDir, ".claude.json")
-
-	mounts := []interface{}{
-		fmt.Sprintf("type=volume,src=dark_nuget_%s,dst=/home/dark/.nuget", name),
-		fmt.Sprintf("type=volume,src=dark-vscode-ext-%s,dst=/home/dark/.vscode-server/extensions", name),
-		fmt.Sprintf("type=volume,src=dark-vscode-ext-insiders-%s,dst=/home/dark/.vscode-server-insiders/extensions", name),
-		// Mount Claude credentials and config (shared across branches)
-		fmt.Sprintf("type=bind,src=%s,dst=/home/dark/.claude,consistency=cached", claudeDir),
-		// Mount .claude.json for auth/theme (writable - Claude needs to save settings)
-		fmt.Sprintf("type=bind,src=%s,dst=/home/dark/.claude.json", claudeJson),
+	var mounts []interface{}
+	for _, mount := range branchMounts(name) {
+		mounts = append(mounts, mount)
 	}
-	// Note: We intentionally do NOT mount ~/.ssh or ~/.gitconfig to avoid leaking credentials.
-	// Git identity (user.name/user.email) is set via postCreateCommand below.
-
 	cfg["mounts"] = mounts
 
 	// Get git identity from host (just user.name and user.email, no credentials)
@@ -244,6 +352,19 @@ func GenerateOverrideConfig(b BranchInfo) (string, error) {
 		cfg["postCreateCommand"] = postCreate
 	}
 
+	// Merge per-branch env vars set via 'multi env'
+	if env := b.Env(); len(env) > 0 {
+		containerEnv, _ := cfg["containerEnv"].(map[string]interface{})
+		if containerEnv == nil {
+			containerEnv = make(map[string]interface{})
+		}
+		for k, v := range env {
+			containerEnv[k] = v
+		}
+		cfg["containerEnv"] = containerEnv
+		logger.Debugf("Injecting %d branch env vars", len(env))
+	}
+
 	// Inject OAuth token if available (from ~/.config/dark-multi/oauth_token)
 	// Combined with mounted ~/.claude.json (which has hasCompletedOnboarding: true),
 	// this enables auto-auth without /login
@@ -257,7 +378,7 @@ func GenerateOverrideConfig(b BranchInfo) (string, error) {
 			}
 			containerEnv["CLAUDE_CODE_OAUTH_TOKEN"] = token
 			cfg["containerEnv"] = containerEnv
-			logToFile("Injecting CLAUDE_CODE_OAUTH_TOKEN from %s", oauthTokenPath)
+			logger.Debugf("Injecting CLAUDE_CODE_OAUTH_TOKEN from %s", oauthTokenPath)
 		}
 	}
 
@@ -1,7 +1,12 @@
 package container
 
 import (
+	"fmt"
 	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/darklang/dark-multi/config"
 )
 
 // StopContainer stops a Docker container by ID.
@@ -37,6 +42,53 @@ func RemoveContainersByLabel(label string) error {
 	return nil
 }
 
+// StartedAt returns when a container was last started.
+func StartedAt(containerID string) (time.Time, error) {
+	out, err := exec.Command("docker", "inspect", "-f", "{{.State.StartedAt}}", containerID).Output()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339Nano, strings.TrimSpace(string(out)))
+}
+
+// RunPlainContainer starts a branch container from a plain Docker image,
+// for projects without a devcontainer.json. Uses the same ports, labels and
+// mounts as the devcontainer path so proxy and tmux work unchanged.
+func RunPlainContainer(b BranchInfo) error {
+	image := config.GetDockerImage()
+	if image == "" {
+		return fmt.Errorf("no .devcontainer/devcontainer.json and no Docker image configured. Set DARK_MULTI_DOCKER_IMAGE")
+	}
+
+	name := b.GetName()
+
+	// Clear out any stopped container left over from a previous run (names must be unique)
+	RemoveContainersByLabel(fmt.Sprintf("dark-dev-container=%s", name))
+
+	args := []string{"run", "-d"}
+	args = append(args, identityRunArgs(name)...)
+	args = append(args, portRunArgs(b)...)
+	args = append(args, limitRunArgs(b)...)
+	for k, v := range b.Env() {
+		args = append(args, "-e", k+"="+v)
+	}
+	for _, mount := range branchMounts(name) {
+		args = append(args, "--mount", mount)
+	}
+	args = append(args,
+		"--mount", fmt.Sprintf("type=bind,src=%s,dst=/home/dark/app", b.GetPath()),
+		"-w", "/home/dark/app",
+		image,
+		"sh", "-c", config.GetDockerCommand(),
+	)
+
+	logger.Infof("Running plain container: docker %v", args)
+	if out, err := exec.Command("docker", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("docker run failed: %s", string(out))
+	}
+	return nil
+}
+
 func splitLines(s string) []string {
 	var lines []string
 	start := 0
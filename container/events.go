@@ -0,0 +1,50 @@
+package container
+
+import (
+	"bufio"
+	"context"
+	"os/exec"
+	"strings"
+)
+
+// Event is a start/stop event for a branch container.
+type Event struct {
+	Branch string // value of the dark-dev-container label
+	Action string // "start" or "die"
+}
+
+// WatchEvents streams start/die events for dark-multi containers via
+// `docker events` until ctx is cancelled. The channel is closed when the
+// stream ends, so callers should keep polling as a fallback.
+func WatchEvents(ctx context.Context) (<-chan Event, error) {
+	cmd := exec.CommandContext(ctx, "docker", "events",
+		"--filter", "label=dark-dev-container",
+		"--filter", "event=start",
+		"--filter", "event=die",
+		"--format", `{{index .Actor.Attributes "dark-dev-container"}} {{.Action}}`,
+	)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) == 2 {
+				select {
+				case events <- Event{Branch: fields[0], Action: fields[1]}:
+				case <-ctx.Done():
+				}
+			}
+		}
+		cmd.Wait()
+	}()
+	return events, nil
+}
@@ -0,0 +1,122 @@
+package container
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Per-branch named volumes are these prefixes followed by the branch name.
+const (
+	nugetVolumePrefix          = "dark_nuget_"
+	vscodeExtVolumePrefix      = "dark-vscode-ext-"
+	vscodeInsidersVolumePrefix = "dark-vscode-ext-insiders-"
+)
+
+var branchVolumePrefixes = []string{nugetVolumePrefix, vscodeExtVolumePrefix, vscodeInsidersVolumePrefix}
+
+// BranchVolumes returns the names of the named volumes a branch's container uses.
+func BranchVolumes(name string) []string {
+	var vols []string
+	for _, p := range branchVolumePrefixes {
+		vols = append(vols, p+name)
+	}
+	return vols
+}
+
+// VolumeBranches returns every branch a per-branch volume could belong to.
+// Names are ambiguous: dark-vscode-ext-insiders-foo is the insiders volume of
+// "foo" or the extensions volume of "insiders-foo".
+func VolumeBranches(volume string) []string {
+	var names []string
+	for _, p := range branchVolumePrefixes {
+		if strings.HasPrefix(volume, p) && len(volume) > len(p) {
+			names = append(names, strings.TrimPrefix(volume, p))
+		}
+	}
+	return names
+}
+
+// VolumeSizes returns the size in bytes of every local volume, parsed from
+// the "Local Volumes space usage" section of `docker system df -v`.
+func VolumeSizes() (map[string]int64, error) {
+	out, err := exec.Command("docker", "system", "df", "-v").Output()
+	if err != nil {
+		return nil, fmt.Errorf("docker system df: %w", err)
+	}
+	sizes := make(map[string]int64)
+	inVolumes := false
+	for _, line := range strings.Split(string(out), "\n") {
+		switch {
+		case strings.HasPrefix(line, "Local Volumes space usage"):
+			inVolumes = true
+		case !inVolumes || strings.HasPrefix(line, "VOLUME NAME"):
+		case strings.TrimSpace(line) == "":
+			if len(sizes) > 0 {
+				return sizes, nil
+			}
+		case strings.Contains(line, "space usage"):
+			return sizes, nil
+		default:
+			fields := strings.Fields(line)
+			if len(fields) >= 2 {
+				sizes[fields[0]] = parseSize(fields[len(fields)-1])
+			}
+		}
+	}
+	return sizes, nil
+}
+
+// parseSize parses docker's human-readable sizes ("0B", "12.5kB", "1.2GB").
+func parseSize(s string) int64 {
+	units := []struct {
+		suffix string
+		mult   float64
+	}{
+		{"TB", 1e12}, {"GB", 1e9}, {"MB", 1e6}, {"kB", 1e3}, {"KB", 1e3}, {"B", 1},
+	}
+	for _, u := range units {
+		if strings.HasSuffix(s, u.suffix) {
+			v, err := strconv.ParseFloat(strings.TrimSuffix(s, u.suffix), 64)
+			if err != nil {
+				return 0
+			}
+			return int64(v * u.mult)
+		}
+	}
+	return 0
+}
+
+// ListVolumes returns the names of all docker volumes.
+func ListVolumes() ([]string, error) {
+	out, err := exec.Command("docker", "volume", "ls", "-q").Output()
+	if err != nil {
+		return nil, fmt.Errorf("docker volume ls: %w", err)
+	}
+	return strings.Fields(string(out)), nil
+}
+
+// RemoveVolume removes a docker volume. It fails if a container still uses it.
+func RemoveVolume(name string) error {
+	out, err := exec.Command("docker", "volume", "rm", name).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// FormatBytes renders a byte count the way docker does, e.g. "1.2GB".
+func FormatBytes(n int64) string {
+	switch {
+	case n >= 1e12:
+		return fmt.Sprintf("%.1fTB", float64(n)/1e12)
+	case n >= 1e9:
+		return fmt.Sprintf("%.1fGB", float64(n)/1e9)
+	case n >= 1e6:
+		return fmt.Sprintf("%.1fMB", float64(n)/1e6)
+	case n >= 1e3:
+		return fmt.Sprintf("%.1fkB", float64(n)/1e3)
+	}
+	return fmt.Sprintf("%dB", n)
+}
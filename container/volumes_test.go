@@ -0,0 +1,59 @@
+package container
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int64
+	}{
+		{"0B", 0},
+		{"512B", 512},
+		{"12.5kB", 12500},
+		{"3KB", 3000},
+		{"1.2GB", 1200000000},
+		{"45MB", 45000000},
+		{"2TB", 2000000000000},
+		{"garbage", 0},
+		{"xGB", 0},
+	}
+	for _, tt := range tests {
+		if got := parseSize(tt.s); got != tt.want {
+			t.Errorf("parseSize(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestVolumeBranches(t *testing.T) {
+	tests := []struct {
+		volume string
+		want   []string
+	}{
+		{"dark_nuget_foo", []string{"foo"}},
+		{"dark-vscode-ext-foo", []string{"foo"}},
+		// Either the insiders volume of foo or the extensions volume of insiders-foo
+		{"dark-vscode-ext-insiders-foo", []string{"insiders-foo", "foo"}},
+		{"dark_nuget_", nil},
+		{"postgres-data", nil},
+	}
+	for _, tt := range tests {
+		if got := VolumeBranches(tt.volume); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("VolumeBranches(%q) = %q, want %q", tt.volume, got, tt.want)
+		}
+	}
+}
+
+func TestBranchVolumesRoundTrip(t *testing.T) {
+	for _, v := range BranchVolumes("feat-x") {
+		found := false
+		for _, name := range VolumeBranches(v) {
+			found = found || name == "feat-x"
+		}
+		if !found {
+			t.Errorf("VolumeBranches(%q) doesn't include feat-x", v)
+		}
+	}
+}
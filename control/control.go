@@ -0,0 +1,178 @@
+// Package control serves a local control API for the running TUI, so multi
+// can be scripted from another terminal while the TUI is open.
+//
+// The protocol is one command per line on a Unix socket, answered with one
+// JSON line:
+//
+//	list            -> [{"name":..., "id":..., "running":...}, ...]
+//	status <name>   -> {"name":..., "id":..., "running":..., "url":...}
+//	start <name>    -> {"name":..., "running":true}
+//	stop <name>     -> {"name":..., "running":false}
+//
+// e.g. echo list | nc -U ~/.config/dark-multi/run/control.sock
+package control
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/darklang/dark-multi/branch"
+	"github.com/darklang/dark-multi/config"
+)
+
+// SocketPath returns the control socket path. It lives in a directory only
+// the user can enter, so the socket is never reachable by others, even
+// between Listen creating it and the chmod.
+func SocketPath() string {
+	return filepath.Join(config.ConfigDir, "run", "control.sock")
+}
+
+// Response is the JSON answer to a command.
+type Response struct {
+	OK     bool   `json:"ok"`
+	Error  string `json:"error,omitempty"`
+	Result any    `json:"result,omitempty"`
+}
+
+// BranchInfo describes a branch in responses.
+type BranchInfo struct {
+	Name    string `json:"name"`
+	ID      int    `json:"id"`
+	Running bool   `json:"running"`
+	URL     string `json:"url,omitempty"`
+}
+
+// Serve listens on SocketPath until ctx is cancelled. It fails if another
+// process is already serving there.
+func Serve(ctx context.Context) error {
+	path := SocketPath()
+	if conn, err := net.DialTimeout("unix", path, 200*time.Millisecond); err == nil {
+		conn.Close()
+		return fmt.Errorf("control socket %s is already in use", path)
+	}
+	os.Remove(path) // Stale socket from a previous run
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	// MkdirAll leaves an existing directory's mode alone
+	if err := os.Chmod(dir, 0700); err != nil {
+		return err
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+	os.Chmod(path, 0600)
+
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+		os.Remove(path)
+	}()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		go handleConn(conn)
+	}
+}
+
+func handleConn(conn net.Conn) {
+	defer conn.Close()
+	scanner := bufio.NewScanner(conn)
+	enc := json.NewEncoder(conn)
+	enc.SetEscapeHTML(false)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		result, err := handle(strings.Fields(line))
+		if err != nil {
+			enc.Encode(Response{Error: err.Error()})
+		} else {
+			enc.Encode(Response{OK: true, Result: result})
+		}
+	}
+}
+
+// handle runs one command.
+func handle(args []string) (any, error) {
+	cmd, args := args[0], args[1:]
+
+	if cmd == "list" {
+		infos := []BranchInfo{}
+		for _, b := range branch.GetManagedBranches() {
+			infos = append(infos, BranchInfo{Name: b.Name, ID: b.InstanceID(), Running: b.IsRunning()})
+		}
+		return infos, nil
+	}
+
+	if cmd != "status" && cmd != "start" && cmd != "stop" {
+		return nil, fmt.Errorf("unknown command %q (use list, status, start, stop)", cmd)
+	}
+	if len(args) != 1 {
+		return nil, fmt.Errorf("usage: %s <name>", cmd)
+	}
+	if err := branch.ValidateName(args[0]); err != nil {
+		return nil, err
+	}
+	b := branch.New(args[0])
+	if !b.Exists() {
+		return nil, fmt.Errorf("branch %s does not exist", b.Name)
+	}
+
+	switch cmd {
+	case "status":
+		info := BranchInfo{Name: b.Name, ID: b.InstanceID(), Running: b.IsRunning()}
+		if info.Running {
+			info.URL = fmt.Sprintf("http://dark-packages.%s.dlio.localhost:%d/ping", b.Name, config.GetProxyPort())
+		}
+		return info, nil
+
+	case "start":
+		if err := branch.Start(b); err != nil {
+			return nil, err
+		}
+		return BranchInfo{Name: b.Name, ID: b.InstanceID(), Running: true}, nil
+
+	default: // stop
+		if err := branch.Stop(b); err != nil {
+			return nil, err
+		}
+		return BranchInfo{Name: b.Name, ID: b.InstanceID(), Running: false}, nil
+	}
+}
+
+// Send sends one command to a running TUI and returns its raw JSON response.
+func Send(command string) (string, error) {
+	conn, err := net.DialTimeout("unix", SocketPath(), time.Second)
+	if err != nil {
+		return "", errors.New("no TUI running (control socket not found)")
+	}
+	defer conn.Close()
+
+	if _, err := fmt.Fprintln(conn, command); err != nil {
+		return "", err
+	}
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(reply), nil
+}
@@ -8,6 +8,17 @@ import (
 	"os/exec"
 	"runtime"
 	"time"
+
+	"github.com/darklang/dark-multi/config"
+)
+
+// dnsmasqLine is the dnsmasq config line dark-multi adds; teardown matches on it.
+const dnsmasqLine = "address=/dlio.localhost/127.0.0.1"
+
+const (
+	darwinResolverFile = "/etc/resolver/dlio.localhost"
+	linuxDnsmasqFile   = "/etc/dnsmasq.d/dark-multi.conf"
+	linuxResolvedFile  = "/etc/systemd/resolved.conf.d/dark-multi.conf"
 )
 
 // TestDNS checks if wildcard DNS is working.
@@ -25,7 +36,8 @@ func TestDNS() bool {
 }
 
 // Setup configures wildcard DNS for *.dlio.localhost -> 127.0.0.1
-func Setup() error {
+// On Windows, branchNames get explicit hosts file entries instead.
+func Setup(branchNames ...string) error {
 	fmt.Printf("Detected platform: %s\n\n", runtime.GOOS)
 
 	// Check if already working
@@ -41,8 +53,10 @@ func Setup() error {
 		err = setupDarwin()
 	case "linux":
 		err = setupLinux()
+	case "windows":
+		err = setupWindows(branchNames)
 	default:
-		return fmt.Errorf("unsupported platform: %s (supported: darwin, linux)", runtime.GOOS)
+		return fmt.Errorf("unsupported platform: %s (supported: darwin, linux, windows)", runtime.GOOS)
 	}
 
 	if err != nil {
@@ -59,7 +73,7 @@ func Setup() error {
 		fmt.Println("\033[0;32m✓\033[0m Wildcard DNS configured successfully!")
 		fmt.Println()
 		fmt.Println("Any *.dlio.localhost now resolves to 127.0.0.1")
-		fmt.Println("Example: http://dark-packages.main.dlio.localhost:9000/ping")
+		fmt.Printf("Example: http://dark-packages.main.dlio.localhost:%d/ping\n", config.GetProxyPort())
 	} else {
 		fmt.Println("\033[1;33m!\033[0m DNS test failed - may need a moment to propagate")
 		fmt.Println("Try: ping test.dlio.localhost")
@@ -97,7 +111,7 @@ func setupDarwin() error {
 	}
 
 	dnsmasqConf := prefix + "/etc/dnsmasq.conf"
-	confLine := "address=/dlio.localhost/127.0.0.1"
+	confLine := dnsmasqLine
 
 	// Check if already configured
 	content, _ := os.ReadFile(dnsmasqConf)
@@ -126,7 +140,7 @@ func setupDarwin() error {
 	// Configure resolver
 	fmt.Println("\033[0;34m>\033[0m Configuring macOS resolver...")
 	exec.Command("sudo", "mkdir", "-p", "/etc/resolver").Run()
-	cmd = exec.Command("sudo", "sh", "-c", "echo 'nameserver 127.0.0.1' > /etc/resolver/dlio.localhost")
+	cmd = exec.Command("sudo", "sh", "-c", "echo 'nameserver 127.0.0.1' > "+darwinResolverFile)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
@@ -154,8 +168,8 @@ func setupLinux() error {
 	}
 
 	// Configure dnsmasq
-	dnsmasqConf := "/etc/dnsmasq.d/dark-multi.conf"
-	confContent := "address=/dlio.localhost/127.0.0.1"
+	dnsmasqConf := linuxDnsmasqFile
+	confContent := dnsmasqLine
 
 	content, _ := os.ReadFile(dnsmasqConf)
 	if !containsLine(string(content), confContent) {
@@ -175,7 +189,7 @@ func setupLinux() error {
 	fmt.Println("\033[0;34m>\033[0m Configuring systemd-resolved...")
 	exec.Command("sudo", "mkdir", "-p", "/etc/systemd/resolved.conf.d").Run()
 	resolvedContent := "[Resolve]\\nDNS=127.0.0.1\\nDomains=~dlio.localhost"
-	cmd := exec.Command("sudo", "sh", "-c", fmt.Sprintf("echo -e '%s' > /etc/systemd/resolved.conf.d/dark-multi.conf", resolvedContent))
+	cmd := exec.Command("sudo", "sh", "-c", fmt.Sprintf("echo -e '%s' > %s", resolvedContent, linuxResolvedFile))
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
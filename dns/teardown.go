@@ -0,0 +1,139 @@
+package dns
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Teardown reverts the DNS changes made by Setup. Only files and lines
+// dark-multi added are removed.
+func Teardown() error {
+	fmt.Printf("Detected platform: %s\n\n", runtime.GOOS)
+
+	var err error
+	switch runtime.GOOS {
+	case "darwin":
+		err = teardownDarwin()
+	case "linux":
+		err = teardownLinux()
+	case "windows":
+		err = teardownWindows()
+	default:
+		return fmt.Errorf("unsupported platform: %s (supported: darwin, linux, windows)", runtime.GOOS)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("\033[0;32m✓\033[0m DNS changes removed")
+	return nil
+}
+
+func teardownDarwin() error {
+	if _, err := os.Stat(darwinResolverFile); err == nil {
+		fmt.Printf("\033[0;34m>\033[0m Removing %s\n", darwinResolverFile)
+		if err := sudoRun("rm", "-f", darwinResolverFile); err != nil {
+			return fmt.Errorf("failed to remove resolver: %w", err)
+		}
+	}
+
+	prefixOut, err := exec.Command("brew", "--prefix").Output()
+	if err != nil {
+		// No Homebrew means setup never installed dnsmasq
+		return nil
+	}
+	dnsmasqConf := strings.TrimSpace(string(prefixOut)) + "/etc/dnsmasq.conf"
+
+	content, err := os.ReadFile(dnsmasqConf)
+	if err != nil || !containsLine(string(content), dnsmasqLine) {
+		return nil
+	}
+
+	fmt.Printf("\033[0;34m>\033[0m Removing dark-multi line from %s\n", dnsmasqConf)
+	var kept []string
+	for _, l := range splitLines(string(content)) {
+		if l != dnsmasqLine {
+			kept = append(kept, l)
+		}
+	}
+	if err := sudoWrite(dnsmasqConf, strings.Join(kept, "\n")+"\n"); err != nil {
+		return fmt.Errorf("failed to update dnsmasq config: %w", err)
+	}
+
+	fmt.Println("\033[0;34m>\033[0m Restarting dnsmasq service...")
+	sudoRun("brew", "services", "restart", "dnsmasq")
+	return nil
+}
+
+func teardownLinux() error {
+	// Only remove the dnsmasq file if it's the one we wrote
+	if content, err := os.ReadFile(linuxDnsmasqFile); err == nil {
+		if containsLine(string(content), dnsmasqLine) {
+			fmt.Printf("\033[0;34m>\033[0m Removing %s\n", linuxDnsmasqFile)
+			if err := sudoRun("rm", "-f", linuxDnsmasqFile); err != nil {
+				return fmt.Errorf("failed to remove dnsmasq config: %w", err)
+			}
+		} else {
+			fmt.Printf("\033[1;33m!\033[0m %s was modified, leaving it alone\n", linuxDnsmasqFile)
+		}
+	}
+
+	if _, err := os.Stat(linuxResolvedFile); err == nil {
+		fmt.Printf("\033[0;34m>\033[0m Removing %s\n", linuxResolvedFile)
+		if err := sudoRun("rm", "-f", linuxResolvedFile); err != nil {
+			return fmt.Errorf("failed to remove resolved drop-in: %w", err)
+		}
+	}
+
+	fmt.Println("\033[0;34m>\033[0m Restarting services...")
+	exec.Command("sudo", "systemctl", "restart", "dnsmasq").Run()
+	exec.Command("sudo", "systemctl", "restart", "systemd-resolved").Run()
+	return nil
+}
+
+func teardownWindows() error {
+	path := windowsHostsPath()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read hosts file: %w", err)
+	}
+
+	var kept []string
+	removed := 0
+	for _, l := range splitLines(string(content)) {
+		if strings.HasSuffix(l, hostsMarker) {
+			removed++
+			continue
+		}
+		kept = append(kept, l)
+	}
+	if removed == 0 {
+		return nil
+	}
+
+	fmt.Printf("\033[0;34m>\033[0m Removing %d entries from %s\n", removed, path)
+	if err := os.WriteFile(path, []byte(strings.Join(kept, "\r\n")+"\r\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write hosts file (run as Administrator): %w", err)
+	}
+	return nil
+}
+
+// sudoRun runs a command under sudo, attached to the terminal for password prompts.
+func sudoRun(args ...string) error {
+	cmd := exec.Command("sudo", args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// sudoWrite replaces a root-owned file's contents via sudo tee.
+func sudoWrite(path, content string) error {
+	cmd := exec.Command("sudo", "tee", path)
+	cmd.Stdin = strings.NewReader(content)
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
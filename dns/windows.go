@@ -0,0 +1,105 @@
+package dns
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// hostsMarker tags hosts file lines written by dark-multi so they can be found again.
+const hostsMarker = "# dark-multi"
+
+// hostsCanvases are the canvases given hosts entries per branch on Windows,
+// since the hosts file can't express *.dlio.localhost.
+var hostsCanvases = []string{"dark-packages"}
+
+// windowsHostsPath returns the path to the Windows hosts file.
+func windowsHostsPath() string {
+	root := os.Getenv("SystemRoot")
+	if root == "" {
+		root = `C:\Windows`
+	}
+	return filepath.Join(root, "System32", "drivers", "etc", "hosts")
+}
+
+// branchHostnames returns the hostnames a branch needs without wildcard DNS.
+func branchHostnames(branchName string) []string {
+	var hosts []string
+	for _, canvas := range hostsCanvases {
+		hosts = append(hosts, fmt.Sprintf("%s.%s.dlio.localhost", canvas, branchName))
+	}
+	return hosts
+}
+
+func setupWindows(branchNames []string) error {
+	fmt.Println("Setting up DNS for Windows...")
+	fmt.Println()
+	fmt.Println("The Windows hosts file doesn't support wildcards, so each branch")
+	fmt.Println("gets explicit entries. For true wildcards, install Acrylic DNS Proxy")
+	fmt.Println("and add: 127.0.0.1 *.dlio.localhost")
+	fmt.Println()
+
+	// test-wildcard lets TestDNS verify the hosts file is being read
+	hosts := []string{"test-wildcard.dlio.localhost"}
+	for _, name := range branchNames {
+		hosts = append(hosts, branchHostnames(name)...)
+	}
+
+	fmt.Printf("\033[0;34m>\033[0m Adding %d entries to %s\n", len(hosts), windowsHostsPath())
+	return addHostsEntries(hosts)
+}
+
+// AddBranchHosts adds hosts file entries for a new branch on Windows.
+// It is a no-op on platforms with wildcard DNS.
+func AddBranchHosts(branchName string) error {
+	if runtime.GOOS != "windows" {
+		return nil
+	}
+	return addHostsEntries(branchHostnames(branchName))
+}
+
+// addHostsEntries appends 127.0.0.1 entries for hosts not already present.
+func addHostsEntries(hosts []string) error {
+	path := windowsHostsPath()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read hosts file: %w", err)
+	}
+
+	existing := make(map[string]bool)
+	for _, line := range splitLines(string(content)) {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && !strings.HasPrefix(fields[0], "#") {
+			for _, h := range fields[1:] {
+				existing[strings.ToLower(h)] = true
+			}
+		}
+	}
+
+	var b strings.Builder
+	if len(content) > 0 && !strings.HasSuffix(string(content), "\n") {
+		b.WriteString("\r\n")
+	}
+	for _, h := range hosts {
+		if existing[strings.ToLower(h)] {
+			continue
+		}
+		fmt.Fprintf(&b, "127.0.0.1 %s %s\r\n", h, hostsMarker)
+		existing[strings.ToLower(h)] = true
+	}
+	if b.Len() == 0 {
+		return nil
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open hosts file (run as Administrator): %w", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(b.String()); err != nil {
+		return fmt.Errorf("failed to write hosts file: %w", err)
+	}
+	return nil
+}
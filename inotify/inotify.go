@@ -62,6 +62,9 @@ func Setup() error {
 	fmt.Printf("Current inotify limits:\n")
 	fmt.Printf("  max_user_watches:   %d\n", watches)
 	fmt.Printf("  max_user_instances: %d\n", instances)
+	if usage, err := CurrentUsage(); err == nil {
+		fmt.Printf("  watches in use:     %d (%d%%)\n", usage.Watches, usage.Percent())
+	}
 	fmt.Println()
 
 	// Check if already sufficient
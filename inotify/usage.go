@@ -0,0 +1,105 @@
+package inotify
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// WarnPercent is the watch usage above which callers should warn.
+const WarnPercent = 80
+
+// Usage is the current inotify watch usage.
+type Usage struct {
+	Watches     int
+	MaxWatches  int
+	ByContainer map[string]int // short container ID -> watches
+}
+
+// Percent returns watch usage as a percentage of the limit.
+func (u Usage) Percent() int {
+	if u.MaxWatches == 0 {
+		return 0
+	}
+	return u.Watches * 100 / u.MaxWatches
+}
+
+// NearLimit reports whether usage is above WarnPercent.
+func (u Usage) NearLimit() bool {
+	return u.Percent() >= WarnPercent
+}
+
+// containerCgroupRegex finds a docker container ID in a /proc/<pid>/cgroup line.
+var containerCgroupRegex = regexp.MustCompile(`docker[-/]([0-9a-f]{64})`)
+
+// CurrentUsage counts inotify watches held by processes we can inspect
+// (our own user's, which includes container processes running as our uid).
+func CurrentUsage() (Usage, error) {
+	if runtime.GOOS != "linux" {
+		return Usage{}, fmt.Errorf("inotify limits only apply to Linux")
+	}
+
+	usage := Usage{ByContainer: make(map[string]int)}
+	data, err := os.ReadFile("/proc/sys/fs/inotify/max_user_watches")
+	if err != nil {
+		return usage, err
+	}
+	usage.MaxWatches, _ = strconv.Atoi(strings.TrimSpace(string(data)))
+
+	pids, _ := filepath.Glob("/proc/[0-9]*")
+	for _, pidDir := range pids {
+		watches := processWatches(pidDir)
+		if watches == 0 {
+			continue
+		}
+		usage.Watches += watches
+		if id := processContainer(pidDir); id != "" {
+			usage.ByContainer[id] += watches
+		}
+	}
+	return usage, nil
+}
+
+// processWatches counts inotify watches across a process's inotify fds.
+func processWatches(pidDir string) int {
+	fds, err := os.ReadDir(filepath.Join(pidDir, "fd"))
+	if err != nil {
+		return 0
+	}
+	total := 0
+	for _, fd := range fds {
+		target, err := os.Readlink(filepath.Join(pidDir, "fd", fd.Name()))
+		if err != nil || target != "anon_inode:inotify" {
+			continue
+		}
+		f, err := os.Open(filepath.Join(pidDir, "fdinfo", fd.Name()))
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if strings.HasPrefix(scanner.Text(), "inotify wd:") {
+				total++
+			}
+		}
+		f.Close()
+	}
+	return total
+}
+
+// processContainer returns the short docker container ID a process runs in, if any.
+func processContainer(pidDir string) string {
+	data, err := os.ReadFile(filepath.Join(pidDir, "cgroup"))
+	if err != nil {
+		return ""
+	}
+	if m := containerCgroupRegex.FindSubmatch(data); m != nil {
+		return string(m[1][:12])
+	}
+	return ""
+}
@@ -0,0 +1,95 @@
+// Package log provides leveled logging to a size-capped file in the config
+// dir, for debugging multi itself. The TUI owns the terminal, so nothing is
+// written to stdout/stderr.
+package log
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/darklang/dark-multi/config"
+)
+
+// Level is a log severity.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+func (l Level) String() string {
+	return levelNames[l]
+}
+
+// ParseLevel parses a level name, defaulting to info.
+func ParseLevel(s string) Level {
+	for i, name := range levelNames {
+		if strings.EqualFold(s, name) {
+			return Level(i)
+		}
+	}
+	return LevelInfo
+}
+
+// MaxSize is how large the log may grow before it's rotated to <file>.1,
+// replacing any previous rotation.
+const MaxSize = 5 * 1024 * 1024
+
+var mu sync.Mutex
+
+// Logger writes log lines tagged with a component name.
+type Logger struct {
+	component string
+}
+
+// New returns a logger for a component, e.g. "branch".
+func New(component string) *Logger {
+	return &Logger{component: component}
+}
+
+func (l *Logger) Debugf(format string, args ...any) { l.log(LevelDebug, format, args...) }
+func (l *Logger) Infof(format string, args ...any)  { l.log(LevelInfo, format, args...) }
+func (l *Logger) Warnf(format string, args ...any)  { l.log(LevelWarn, format, args...) }
+func (l *Logger) Errorf(format string, args ...any) { l.log(LevelError, format, args...) }
+
+func (l *Logger) log(level Level, format string, args ...any) {
+	if level < ParseLevel(config.LogLevel) {
+		return
+	}
+	line := fmt.Sprintf("%s %-5s [%s] %s\n", time.Now().Format("2006-01-02 15:04:05"),
+		level, l.component, fmt.Sprintf(format, args...))
+
+	RotatingFile{Path: config.LogFile}.Write([]byte(line))
+}
+
+// RotatingFile is an io.Writer that appends to Path, first rotating it to
+// Path.1 if the write would take it past MaxSize. Other append-only logs,
+// like the proxy's access log, use it to stay size-capped too.
+type RotatingFile struct {
+	Path string
+}
+
+func (r RotatingFile) Write(p []byte) (int, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if info, err := os.Stat(r.Path); err == nil && info.Size()+int64(len(p)) > MaxSize {
+		os.Rename(r.Path, r.Path+".1")
+	}
+	os.MkdirAll(filepath.Dir(r.Path), 0755)
+	f, err := os.OpenFile(r.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	return f.Write(p)
+}
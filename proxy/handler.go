@@ -1,17 +1,50 @@
 package proxy
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"strings"
 	"time"
 )
 
 // ProxyHandler handles proxy requests.
-type ProxyHandler struct{}
+type ProxyHandler struct {
+	logger *log.Logger // access log; nil disables logging
+}
+
+// statusRecorder captures the status code written to a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
 
 func (h *ProxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.logger == nil {
+		h.serve(w, r)
+		return
+	}
+
+	rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	start := time.Now()
+	port := h.serve(rec, r)
+	target := "-"
+	if port != 0 {
+		target = fmt.Sprintf(":%d", port)
+	}
+	h.logger.Printf("%s %s %s %d -> %s %s", r.Method, r.Host, r.URL.RequestURI(), rec.status, target, time.Since(start).Round(time.Millisecond))
+}
+
+// serve proxies the request, returning the backend port it was routed to
+// (0 if it never got that far).
+func (h *ProxyHandler) serve(w http.ResponseWriter, r *http.Request) int {
 	host := r.Host
 
 	// Strip port if present
@@ -21,11 +54,18 @@ func (h *ProxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	// Parse hostname: <canvas>.<branch>.dlio.localhost
 	parts := strings.Split(host, ".")
+	isBranchHost := len(parts) >= 4 && parts[len(parts)-2] == "dlio" && parts[len(parts)-1] == "localhost"
+
+	// Built-in endpoints, on any host that isn't routed to a branch
+	if !isBranchHost && strings.HasPrefix(r.URL.Path, "/_multi/") {
+		serveMulti(w, r)
+		return 0
+	}
 
 	// Must have at least 4 parts and end with dlio.localhost
-	if len(parts) < 4 || parts[len(parts)-2] != "dlio" || parts[len(parts)-1] != "localhost" {
+	if !isBranchHost {
 		http.Error(w, fmt.Sprintf("Invalid hostname format: %s\nExpected: <canvas>.<branch>.dlio.localhost", host), http.StatusBadRequest)
-		return
+		return 0
 	}
 
 	// Find dlio index and extract branch name
@@ -39,7 +79,7 @@ func (h *ProxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	if dlioIdx < 2 {
 		http.Error(w, fmt.Sprintf("Invalid hostname format: %s", host), http.StatusBadRequest)
-		return
+		return 0
 	}
 
 	branchName := parts[dlioIdx-1]
@@ -47,16 +87,16 @@ func (h *ProxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	canvasHost := strings.Join(canvasParts, ".")
 
 	// Look up port for branch
-	port, ok := BranchPorts[branchName]
+	port, ok := lookupPort(branchName)
 	if !ok {
 		// Refresh cache and try again
 		RefreshBranchPorts()
-		port, ok = BranchPorts[branchName]
+		port, ok = lookupPort(branchName)
 	}
 
 	if !ok {
 		http.Error(w, fmt.Sprintf("Branch '%s' not running.\nRunning branches: %v", branchName, getBranchNames()), http.StatusNotFound)
-		return
+		return 0
 	}
 
 	// Forward request
@@ -68,7 +108,7 @@ func (h *ProxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	proxyReq, err := http.NewRequest(r.Method, targetURL, r.Body)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Proxy error: %v", err), http.StatusInternalServerError)
-		return
+		return port
 	}
 
 	// Copy headers, replacing Host
@@ -88,7 +128,7 @@ func (h *ProxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	resp, err := client.Do(proxyReq)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Backend error: %v", err), http.StatusBadGateway)
-		return
+		return port
 	}
 	defer resp.Body.Close()
 
@@ -105,11 +145,28 @@ func (h *ProxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Copy response
 	w.WriteHeader(resp.StatusCode)
 	io.Copy(w, resp.Body)
+	return port
+}
+
+// serveMulti handles the proxy's own endpoints:
+//
+//	/_multi/health  200 if the proxy is up
+//	/_multi/routes  JSON of branch name -> port currently routed
+func serveMulti(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/_multi/health":
+		fmt.Fprintln(w, "ok")
+	case "/_multi/routes":
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(BranchPorts())
+	default:
+		http.NotFound(w, r)
+	}
 }
 
 func getBranchNames() []string {
 	var names []string
-	for name := range BranchPorts {
+	for name := range BranchPorts() {
 		names = append(names, name)
 	}
 	return names
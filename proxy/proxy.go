@@ -2,34 +2,100 @@
 package proxy
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
+	"log"
 	"net"
 	"net/http"
 	"os"
 	"os/exec"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 
 	"github.com/darklang/dark-multi/branch"
 	"github.com/darklang/dark-multi/config"
+	"github.com/darklang/dark-multi/container"
+	multilog "github.com/darklang/dark-multi/log"
 )
 
-// BranchPorts caches branch name -> port mappings.
-var BranchPorts = make(map[string]int)
+// branchPorts caches branch name -> port mappings. Requests are served
+// concurrently, so access goes through portsMu.
+var (
+	portsMu     sync.RWMutex
+	branchPorts = make(map[string]int)
+)
 
 // RefreshBranchPorts updates the branch port cache.
 func RefreshBranchPorts() {
-	BranchPorts = make(map[string]int)
+	ports := make(map[string]int)
 	for _, b := range branch.GetManagedBranches() {
 		if b.IsRunning() {
-			BranchPorts[b.Name] = b.BwdPortBase()
+			ports[b.Name] = b.BwdPortBase()
 		}
 	}
+	portsMu.Lock()
+	branchPorts = ports
+	portsMu.Unlock()
+}
+
+// BranchPorts returns a copy of the current branch name -> port routes.
+func BranchPorts() map[string]int {
+	portsMu.RLock()
+	defer portsMu.RUnlock()
+	ports := make(map[string]int, len(branchPorts))
+	for name, port := range branchPorts {
+		ports[name] = port
+	}
+	return ports
+}
+
+// lookupPort returns the cached port for a branch.
+func lookupPort(name string) (int, bool) {
+	portsMu.RLock()
+	defer portsMu.RUnlock()
+	port, ok := branchPorts[name]
+	return port, ok
+}
+
+// watchRoutes keeps the route cache current without a restart: it refreshes
+// when a branch container starts or dies (docker events) and, on Unix, on
+// SIGUSR1, for 'kill -USR1 $(cat ~/.config/dark-multi/proxy.pid)'.
+func watchRoutes() {
+	refresh := make(chan os.Signal, 1)
+	notifyRefresh(refresh)
+
+	var events <-chan container.Event
+	if ch, err := container.WatchEvents(context.Background()); err == nil {
+		events = ch
+	}
+
+	for {
+		select {
+		case <-refresh:
+		case _, ok := <-events:
+			if !ok {
+				events = nil // docker events went away; SIGUSR1 and lookups still refresh
+				continue
+			}
+		}
+		RefreshBranchPorts()
+	}
+}
+
+// Options are the optional proxy features.
+type Options struct {
+	TLSPort int  // if non-zero, HTTPS is also served here using the local CA
+	Log     bool // append an access log to config.ProxyLogFile
+	// SavePort saves port as the configured proxy port once it's bound, so
+	// URLs never point at a port nothing listens on
+	SavePort bool
 }
 
 // Start starts the proxy server. Returns PID if backgrounded.
-func Start(port int, background bool) (int, error) {
+func Start(port int, background bool, opts Options) (int, error) {
 	if err := os.MkdirAll(config.ConfigDir, 0755); err != nil {
 		return 0, err
 	}
@@ -41,10 +107,19 @@ func Start(port int, background bool) (int, error) {
 			return 0, err
 		}
 
-		cmd := exec.Command(execPath, "proxy", "fg")
-		cmd.SysProcAttr = &syscall.SysProcAttr{
-			Setsid: true,
+		args := []string{"proxy", "fg"}
+		if opts.TLSPort != 0 {
+			args = append(args, "--tls")
 		}
+		if opts.Log {
+			args = append(args, "--log")
+		}
+		if opts.SavePort {
+			// The child binds, so it's the one that saves
+			args = append(args, "--port", strconv.Itoa(port))
+		}
+		cmd := exec.Command(execPath, args...)
+		cmd.SysProcAttr = detachAttrs()
 
 		// Redirect to /dev/null
 		devnull, _ := os.Open(os.DevNull)
@@ -63,11 +138,41 @@ func Start(port int, background bool) (int, error) {
 
 	// Foreground mode - run the server
 	RefreshBranchPorts()
+	go watchRoutes()
+
+	handler := &ProxyHandler{}
+	if opts.Log {
+		// Check the log is writable now rather than on the first request
+		f, err := os.OpenFile(config.ProxyLogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return 0, fmt.Errorf("failed to open proxy log: %w", err)
+		}
+		f.Close()
+		handler.logger = log.New(multilog.RotatingFile{Path: config.ProxyLogFile}, "", log.LstdFlags)
+	}
+
+	if opts.TLSPort != 0 {
+		ca, err := loadOrCreateCA()
+		if err != nil {
+			return 0, fmt.Errorf("failed to load proxy CA: %w", err)
+		}
+		tlsLn, err := tls.Listen("tcp", fmt.Sprintf(":%d", opts.TLSPort), &tls.Config{
+			GetCertificate: ca.getCertificate,
+		})
+		if err != nil {
+			return 0, err
+		}
+		go func() {
+			if err := http.Serve(tlsLn, handler); err != nil {
+				fmt.Fprintf(os.Stderr, "TLS proxy stopped: %v\n", err)
+			}
+		}()
+	}
 
 	// Listen on both IPv4 and IPv6
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%d", port),
-		Handler: &ProxyHandler{},
+		Handler: handler,
 	}
 
 	// Use a listener that supports dual-stack
@@ -75,6 +180,11 @@ func Start(port int, background bool) (int, error) {
 	if err != nil {
 		return 0, err
 	}
+	if opts.SavePort {
+		if err := config.SetProxyPort(port); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to save proxy port: %v\n", err)
+		}
+	}
 
 	return 0, server.Serve(ln)
 }
@@ -141,12 +251,12 @@ func EnsureRunning() error {
 	if _, running := IsRunning(); running {
 		return nil
 	}
-	pid, err := Start(config.ProxyPort, true)
+	pid, err := Start(config.GetProxyPort(), true, Options{})
 	if err != nil {
 		return err
 	}
 	if pid > 0 {
-		fmt.Printf("> Started proxy on port %d (PID %d)\n", config.ProxyPort, pid)
+		fmt.Printf("> Started proxy on port %d (PID %d)\n", config.GetProxyPort(), pid)
 	}
 	return nil
 }
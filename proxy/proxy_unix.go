@@ -0,0 +1,11 @@
+//go:build !windows
+
+package proxy
+
+import "syscall"
+
+// detachAttrs starts the background proxy in its own session, so it outlives
+// the terminal that launched it.
+func detachAttrs() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
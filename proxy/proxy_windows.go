@@ -0,0 +1,9 @@
+package proxy
+
+import "syscall"
+
+// detachAttrs starts the background proxy in its own process group, so
+// Ctrl+C in the launching console doesn't reach it.
+func detachAttrs() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
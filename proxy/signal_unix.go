@@ -0,0 +1,14 @@
+//go:build !windows
+
+package proxy
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyRefresh delivers SIGUSR1 to ch, so route refreshes can be scripted.
+func notifyRefresh(ch chan<- os.Signal) {
+	signal.Notify(ch, syscall.SIGUSR1)
+}
@@ -0,0 +1,7 @@
+package proxy
+
+import "os"
+
+// notifyRefresh is a no-op: Windows has no SIGUSR1, so routes refresh only on
+// docker events and lookup misses.
+func notifyRefresh(ch chan<- os.Signal) {}
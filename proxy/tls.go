@@ -0,0 +1,164 @@
+package proxy
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/darklang/dark-multi/config"
+)
+
+// A single *.dlio.localhost wildcard can't cover <canvas>.<branch>.dlio.localhost,
+// so the proxy keeps a local CA and signs a leaf cert per hostname on demand.
+// Trusting the CA once covers every branch.
+
+// CACertPath returns the path to the proxy's self-signed CA certificate.
+func CACertPath() string {
+	return filepath.Join(config.ConfigDir, "proxy-ca.pem")
+}
+
+func caKeyPath() string {
+	return filepath.Join(config.ConfigDir, "proxy-ca-key.pem")
+}
+
+// certAuthority signs leaf certificates for proxied hostnames.
+type certAuthority struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+
+	mu    sync.Mutex
+	cache map[string]*tls.Certificate
+}
+
+// EnsureCA loads the proxy CA from the config dir, generating it on first run.
+func EnsureCA() error {
+	_, err := loadOrCreateCA()
+	return err
+}
+
+func loadOrCreateCA() (*certAuthority, error) {
+	certPEM, certErr := os.ReadFile(CACertPath())
+	keyPEM, keyErr := os.ReadFile(caKeyPath())
+	if certErr == nil && keyErr == nil {
+		return parseCA(certPEM, keyPEM)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          randomSerial(),
+		Subject:               pkix.Name{CommonName: "dark-multi local CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().AddDate(10, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+		PermittedDNSDomains:   []string{"dlio.localhost"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(config.ConfigDir, 0755); err != nil {
+		return nil, err
+	}
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := os.WriteFile(CACertPath(), certPEM, 0644); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(caKeyPath(), keyPEM, 0600); err != nil {
+		return nil, err
+	}
+	return parseCA(certPEM, keyPEM)
+}
+
+func parseCA(certPEM, keyPEM []byte) (*certAuthority, error) {
+	certBlock, _ := pem.Decode(certPEM)
+	keyBlock, _ := pem.Decode(keyPEM)
+	if certBlock == nil || keyBlock == nil {
+		return nil, fmt.Errorf("invalid CA files in %s", config.ConfigDir)
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParseECPrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	return &certAuthority{cert: cert, key: key, cache: make(map[string]*tls.Certificate)}, nil
+}
+
+// getCertificate returns a leaf cert for the requested SNI hostname.
+func (ca *certAuthority) getCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	host := strings.ToLower(hello.ServerName)
+	if host == "" || !strings.HasSuffix(host, ".dlio.localhost") {
+		return nil, fmt.Errorf("unsupported TLS hostname: %q", host)
+	}
+
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	if cert, ok := ca.cache[host]; ok {
+		return cert, nil
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: randomSerial(),
+		Subject:      pkix.Name{CommonName: host},
+		DNSNames:     []string{host},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().AddDate(1, 0, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		return nil, err
+	}
+
+	cert := &tls.Certificate{Certificate: [][]byte{der, ca.cert.Raw}, PrivateKey: key}
+	ca.cache[host] = cert
+	return cert, nil
+}
+
+func randomSerial() *big.Int {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return big.NewInt(time.Now().UnixNano())
+	}
+	return serial
+}
+
+// TrustInstructions returns platform-specific steps for trusting the proxy CA.
+func TrustInstructions() string {
+	ca := CACertPath()
+	return fmt.Sprintf(`To trust the proxy certificate:
+  macOS:   sudo security add-trusted-cert -d -r trustRoot -k /Library/Keychains/System.keychain %s
+  Linux:   sudo cp %s /usr/local/share/ca-certificates/dark-multi.crt && sudo update-ca-certificates
+           Chrome/Firefox: certutil -d sql:$HOME/.pki/nssdb -A -t C,, -n dark-multi -i %s
+  Windows: certutil -addstore -f ROOT %s`, ca, ca, ca, ca)
+}
@@ -0,0 +1,15 @@
+// Package text provides string helpers shared by dark-multi's packages.
+package text
+
+// Truncate shortens s to n runes, ending with "…" when cut. Use it instead of
+// byte slicing, which splits multibyte characters into garbage.
+func Truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	if n <= 0 {
+		return ""
+	}
+	return string(runes[:n-1]) + "…"
+}
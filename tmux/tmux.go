@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"syscall"
 
 	"github.com/darklang/dark-multi/config"
 )
@@ -33,7 +34,9 @@ func sessionExists(name string) bool {
 	return cmd.Run() == nil
 }
 
-// OpenClaude opens or attaches to the Claude session for a branch.
+// OpenClaude opens or attaches to the Claude session for a branch. A new
+// session runs claude straight away, so check EnsureClaudeInstalled first; without
+// it the pane just shows "command not found".
 func OpenClaude(branchName, containerID string) error {
 	if !IsAvailable() {
 		return fmt.Errorf("tmux not available")
@@ -57,6 +60,52 @@ func OpenClaude(branchName, containerID string) error {
 	return openInTerminal(session)
 }
 
+// ClaudeInstalled returns true if the claude CLI is found in the container.
+func ClaudeInstalled(containerID string) bool {
+	return exec.Command("docker", "exec", containerID, "bash", "-lc", "which claude").Run() == nil
+}
+
+// EnsureClaudeInstalled checks that claude is on the container's PATH and
+// reinstalls it if it's missing. It blocks for the whole reinstall.
+func EnsureClaudeInstalled(containerID string) error {
+	if ClaudeInstalled(containerID) {
+		return nil
+	}
+	return InstallClaude(containerID)
+}
+
+// InstallClaude reinstalls the claude CLI in a container, for when
+// postCreateCommand's npm install failed. It takes a while, so keep it off
+// the UI goroutine.
+func InstallClaude(containerID string) error {
+	// -n: fail rather than wait on a password prompt nobody can answer
+	out, err := exec.Command("docker", "exec", containerID,
+		"sudo", "-n", "npm", "install", "-g", "@anthropic-ai/claude-code").CombinedOutput()
+	if err == nil && ClaudeInstalled(containerID) {
+		return nil
+	}
+
+	output := strings.TrimSpace(string(out))
+	switch {
+	case strings.Contains(output, "password is required") || strings.Contains(output, "terminal is required"):
+		return fmt.Errorf("claude is not installed in the container and reinstall needs a sudo password")
+	case isNetworkError(output):
+		return fmt.Errorf("claude is not installed in the container and reinstall couldn't reach the npm registry (network down?)")
+	}
+	lines := strings.Split(output, "\n")
+	return fmt.Errorf("claude is not installed in the container and reinstall failed: %s", lines[len(lines)-1])
+}
+
+// isNetworkError reports whether npm output shows it couldn't reach the registry.
+func isNetworkError(output string) bool {
+	for _, code := range []string{"ENOTFOUND", "EAI_AGAIN", "ETIMEDOUT", "ECONNREFUSED", "ECONNRESET", "ENETUNREACH"} {
+		if strings.Contains(output, code) {
+			return true
+		}
+	}
+	return false
+}
+
 // OpenTerminal opens or attaches to the terminal session for a branch.
 func OpenTerminal(branchName, containerID string) error {
 	if !IsAvailable() {
@@ -95,13 +144,41 @@ func openInTerminal(session string) error {
 	return spawnTerminalForSession(session)
 }
 
+// Attach replaces the current process with tmux attached to a branch session,
+// so detaching returns to the calling shell. Inside tmux it switches client instead.
+func Attach(branchName, sessionType string) error {
+	if !IsAvailable() {
+		return fmt.Errorf("tmux not available")
+	}
+
+	session := sessionName(branchName, sessionType)
+	if !sessionExists(session) {
+		return fmt.Errorf("no %s session for %s", sessionType, branchName)
+	}
+
+	tmuxPath, err := exec.LookPath("tmux")
+	if err != nil {
+		return err
+	}
+	args := []string{"tmux", "attach", "-t", session}
+	if os.Getenv("TMUX") != "" {
+		args = []string{"tmux", "switch-client", "-t", session}
+	}
+	return syscall.Exec(tmuxPath, args, os.Environ())
+}
+
 // CapturePaneContent captures content from the Claude session for a branch.
-func CapturePaneContent(branchName string, lines int) string {
+// With colors, the pane's escape sequences are kept.
+func CapturePaneContent(branchName string, lines int, colors bool) string {
 	session := sessionName(branchName, SessionClaude)
 	if !sessionExists(session) {
 		return ""
 	}
-	cmd := exec.Command("tmux", "capture-pane", "-t", session, "-p", "-S", fmt.Sprintf("-%d", lines))
+	args := []string{"capture-pane", "-t", session, "-p", "-S", fmt.Sprintf("-%d", lines)}
+	if colors {
+		args = append(args, "-e")
+	}
+	cmd := exec.Command("tmux", args...)
 	out, err := cmd.Output()
 	if err != nil {
 		return ""
@@ -240,6 +317,9 @@ func CreateBranchSession(branchName string, containerID string, branchPath strin
 	if sessionExists(session) {
 		return nil
 	}
+	if err := EnsureClaudeInstalled(containerID); err != nil {
+		return err
+	}
 	if err := exec.Command("tmux", "new-session", "-d", "-s", session).Run(); err != nil {
 		return err
 	}
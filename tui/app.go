@@ -1,16 +1,60 @@
 package tui
 
 import (
+	"context"
+
 	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/darklang/dark-multi/config"
+	"github.com/darklang/dark-multi/container"
+	"github.com/darklang/dark-multi/control"
+	"github.com/darklang/dark-multi/log"
+	"github.com/darklang/dark-multi/proxy"
 )
 
+var logger = log.New("tui")
+
+// containerEventMsg is sent when a branch container starts or dies.
+type containerEventMsg container.Event
+
 // Run starts the TUI application.
 func Run() error {
+	for _, w := range config.LoadKeybindings().Warnings {
+		logger.Warnf("%s", w)
+	}
+
 	p := tea.NewProgram(
 		NewGridModel(),
 		tea.WithAltScreen(),
 	)
 
+	// Background work is tied to ctx so nothing outlives the TUI
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	defer shutdown()
+
+	// Let other terminals script us; a second TUI just doesn't serve
+	go control.Serve(ctx)
+	go watchUptime(ctx, p)
+
+	// React to container start/die immediately; periodic ticks still cover
+	// the case where docker events isn't available.
+	if events, err := container.WatchEvents(ctx); err == nil {
+		go func() {
+			for ev := range events {
+				p.Send(containerEventMsg(ev))
+			}
+		}()
+	}
+
 	_, err := p.Run()
 	return err
 }
+
+// shutdown runs when the TUI exits. The proxy serves branch URLs outside the
+// TUI too, so it's left running unless DARK_MULTI_STOP_PROXY_ON_EXIT is set.
+func shutdown() {
+	if config.StopProxyOnExit {
+		proxy.Stop()
+	}
+}
@@ -0,0 +1,183 @@
+package tui
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/darklang/dark-multi/branch"
+	"github.com/darklang/dark-multi/text"
+)
+
+var (
+	diffAddStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	diffDelStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	diffHunkStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+	diffFileStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("99"))
+)
+
+// DiffViewerModel shows a branch's diff against main inside the TUI,
+// for when gitk can't open a window (e.g. over SSH).
+type DiffViewerModel struct {
+	branch *branch.Branch
+	lines  []string
+	offset int
+	width  int
+	height int
+}
+
+// NewDiffViewerModel loads the committed and uncommitted diff for a branch.
+func NewDiffViewerModel(b *branch.Branch) DiffViewerModel {
+	m := DiffViewerModel{branch: b}
+
+	committed := gitDiff(b.Path, "origin/main...HEAD")
+	uncommitted := gitDiff(b.Path, "HEAD")
+
+	m.lines = append(m.lines, diffFileStyle.Render("═══ Committed (vs origin/main) ═══"))
+	m.lines = append(m.lines, committed...)
+	m.lines = append(m.lines, "", diffFileStyle.Render("═══ Uncommitted ═══"))
+	m.lines = append(m.lines, uncommitted...)
+	return m
+}
+
+// gitDiff runs git diff against rev, returning its lines or a placeholder.
+func gitDiff(path, rev string) []string {
+	out, err := exec.Command("git", "-C", path, "diff", rev).CombinedOutput()
+	if err != nil {
+		return []string{errorStyle.Render(strings.TrimSpace(string(out)))}
+	}
+	text := strings.TrimRight(string(out), "\n")
+	if text == "" {
+		return []string{stoppedStyle.Render("  (no changes)")}
+	}
+	return strings.Split(text, "\n")
+}
+
+// Init implements tea.Model.
+func (m DiffViewerModel) Init() tea.Cmd {
+	return nil
+}
+
+// pageSize is how many diff lines fit on screen.
+func (m DiffViewerModel) pageSize() int {
+	if m.height < 10 {
+		return 30
+	}
+	// Title, blank line, blank line, help
+	return m.height - 4
+}
+
+func (m DiffViewerModel) maxOffset() int {
+	return max(0, len(m.lines)-m.pageSize())
+}
+
+// isHunkStart reports whether a line begins a file or hunk.
+func isHunkStart(line string) bool {
+	return strings.HasPrefix(line, "@@") || strings.HasPrefix(line, "diff --git")
+}
+
+// Update handles input.
+func (m DiffViewerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c":
+			return m, tea.Quit
+
+		case "esc", "backspace", "h", "left":
+			// Back to grid
+			grid := NewGridModel()
+			return grid, grid.Init()
+
+		case "down", "j":
+			m.offset = min(m.offset+1, m.maxOffset())
+
+		case "up", "k":
+			m.offset = max(m.offset-1, 0)
+
+		case "pgdown", " ", "f":
+			m.offset = min(m.offset+m.pageSize(), m.maxOffset())
+
+		case "pgup", "b":
+			m.offset = max(m.offset-m.pageSize(), 0)
+
+		case "n":
+			// Next hunk
+			for i := m.offset + 1; i < len(m.lines); i++ {
+				if isHunkStart(m.lines[i]) {
+					m.offset = min(i, m.maxOffset())
+					break
+				}
+			}
+
+		case "p", "N":
+			// Previous hunk
+			for i := m.offset - 1; i >= 0; i-- {
+				if isHunkStart(m.lines[i]) {
+					m.offset = i
+					break
+				}
+			}
+
+		case "g", "home":
+			m.offset = 0
+
+		case "G", "end":
+			m.offset = m.maxOffset()
+		}
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.offset = min(m.offset, m.maxOffset())
+	}
+
+	return m, nil
+}
+
+// View renders the visible part of the diff.
+func (m DiffViewerModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render(fmt.Sprintf("── %s diff ──", m.branch.Name)))
+	b.WriteString(helpStyle.Render(fmt.Sprintf("  %d/%d", min(m.offset+m.pageSize(), len(m.lines)), len(m.lines))))
+	b.WriteString("\n\n")
+
+	width := m.width
+	if width < 20 {
+		width = 120
+	}
+
+	end := min(m.offset+m.pageSize(), len(m.lines))
+	for _, line := range m.lines[m.offset:end] {
+		if !strings.Contains(line, "\x1b") {
+			line = text.Truncate(line, width)
+		}
+		b.WriteString(renderDiffLine(line))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("  j/k scroll  space/b page  n/p next/prev hunk  g/G top/bottom  ← back  [q]uit"))
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// renderDiffLine colors a unified diff line by its prefix.
+func renderDiffLine(line string) string {
+	switch {
+	case strings.HasPrefix(line, "diff --git"), strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+		return diffFileStyle.Render(line)
+	case strings.HasPrefix(line, "@@"):
+		return diffHunkStyle.Render(line)
+	case strings.HasPrefix(line, "+"):
+		return diffAddStyle.Render(line)
+	case strings.HasPrefix(line, "-"):
+		return diffDelStyle.Render(line)
+	}
+	return line
+}
@@ -2,7 +2,10 @@ package tui
 
 import (
 	"fmt"
+	"math"
 	"os/exec"
+	"runtime"
+	"sort"
 	"strings"
 	"time"
 
@@ -10,7 +13,10 @@ import (
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/darklang/dark-multi/branch"
+	"github.com/darklang/dark-multi/claude"
 	"github.com/darklang/dark-multi/config"
+	"github.com/darklang/dark-multi/container"
+	"github.com/darklang/dark-multi/inotify"
 	"github.com/darklang/dark-multi/tmux"
 )
 
@@ -35,6 +41,9 @@ var (
 	globalPendingBranches = make(map[string]*PendingBranch)
 )
 
+// doubleClickWindow is how close two clicks must be to count as a double-click.
+const doubleClickWindow = 400 * time.Millisecond
+
 // GridInputMode represents input modes.
 type GridInputMode int
 
@@ -42,8 +51,27 @@ const (
 	GridInputNone GridInputMode = iota
 	GridInputNewBranch
 	GridInputConfirmDelete
+	GridInputConfirmStart
+	GridInputConfirmStopAll
+	GridInputSearch
+)
+
+// GridSortMode is the order branches are shown in.
+type GridSortMode int
+
+const (
+	GridSortName GridSortMode = iota
+	GridSortStatus
+	GridSortActivity
+	GridSortChurn
 )
 
+var gridSortNames = []string{"name", "status", "activity", "churn"}
+
+func (s GridSortMode) String() string {
+	return gridSortNames[s]
+}
+
 // ContainerStats holds CPU/memory usage for a container.
 type ContainerStats struct {
 	CPU    string // e.g., "12.5%"
@@ -55,6 +83,7 @@ type GridModel struct {
 	branches        []*branch.Branch
 	paneContent     map[string]string         // branch name -> captured content
 	containerStats  map[string]ContainerStats // branch name -> stats
+	gitStats        map[string]branch.GitSummary
 	cursor          int
 	width           int
 	height          int
@@ -64,32 +93,227 @@ type GridModel struct {
 	inputText       string
 	proxyRunning    bool
 	loading         bool
+	confirmStart    []*branch.Branch   // branches awaiting overcommit confirmation
+	staleness       map[string]string  // branch name -> staleness description, only for stale branches
+	claudeCost      map[string]float64 // branch name -> estimated Claude spend in $
+	searchOrigin    int                // cursor before search started, restored on esc
+	sortMode        GridSortMode
+	listMode        bool                 // one row per branch, toggled with L
+	startedAt       map[string]time.Time // branch name -> container start, for uptime
+	running         map[string]bool      // branch name -> container running, refreshed each tick
+	keys            config.Keybindings
+	claudeStates    map[string]string // branch name -> Claude state (waiting/working/idle)
+	inotifyUsage    inotify.Usage
+	inotifyChecked  time.Time // when inotify usage was last counted
+	refreshInterval time.Duration
+	heavyRefreshed  time.Time            // when panes and docker stats were last loaded
+	lastClickIdx    int                  // cell of the previous click, for double-click detection
+	lastClickAt     time.Time            // when the previous click happened
+	activity        map[string]time.Time // branch name -> last Claude activity, for sorting
+	churn           map[string]int       // branch name -> lines changed vs main, for sorting
 }
 
 // Grid layout messages
 type paneContentMsg map[string]string
 type containerStatsMsg map[string]ContainerStats
+type gridGitStatsMsg map[string]branch.GitSummary
 type gridTickMsg time.Time
+type stalenessMsg map[string]string
+type claudeCostMsg map[string]float64
+type claudeStatesMsg struct {
+	states  map[string]string // branch name -> waiting/working/idle
+	reasons map[string]string // branch name -> Claude's last message or tool
+}
+type inotifyUsageMsg inotify.Usage
+type startTimesMsg map[string]time.Time
+type runningMsg map[string]bool
+type diskUsageMsg struct {
+	name  string
+	usage branch.DiskSize
+	err   error
+}
+
+// claudeReadyMsg reports whether claude can run in a branch's container.
+type claudeReadyMsg struct {
+	name        string
+	containerID string
+	missing     bool  // not installed; a reinstall should be tried
+	err         error // the reinstall failed
+}
+type sortDataMsg struct {
+	mode     GridSortMode // the sort the data was loaded for
+	activity map[string]time.Time
+	churn    map[string]int
+}
 
-// NewGridModel creates a new grid view.
+// NewGridModel creates a new grid view, restoring the saved sort order and layout.
 func NewGridModel() GridModel {
-	return GridModel{
-		branches:       branch.GetManagedBranches(),
+	prefs := loadUIPrefs()
+	m := GridModel{
 		paneContent:    make(map[string]string),
 		containerStats: make(map[string]ContainerStats),
+		gitStats:       make(map[string]branch.GitSummary),
+		staleness:      make(map[string]string),
+		claudeCost:     make(map[string]float64),
+		claudeStates:   make(map[string]string),
+		activity:       make(map[string]time.Time),
+		churn:          make(map[string]int),
+		startedAt:      make(map[string]time.Time),
+		running:        branch.RunningNames(),
+		sortMode:       parseGridSortMode(prefs.Sort),
+		listMode:       prefs.List,
+		keys:           config.LoadKeybindings(),
+
+		// Init loads panes and stats, so the first tick needn't
+		refreshInterval: config.GetGridRefreshInterval(),
+		heavyRefreshed:  time.Now(),
+	}
+	m.setBranches(branch.GetManagedBranches())
+	return m
+}
+
+// setBranches replaces the branch list, ordering it by the current sort mode
+// and keeping the cursor on the same branch where possible.
+func (m *GridModel) setBranches(branches []*branch.Branch) {
+	selected := ""
+	if m.cursor < len(m.branches) {
+		selected = m.branches[m.cursor].Name
+	}
+
+	// Branches arrive sorted by name, so a stable sort keeps name as the tiebreak
+	switch m.sortMode {
+	case GridSortStatus:
+		// One docker ps up front; IsRunning per comparison is O(n log n)
+		// processes and can change mid-sort
+		running := branch.RunningNames()
+		sort.SliceStable(branches, func(i, j int) bool {
+			return running[branches[i].Name] && !running[branches[j].Name]
+		})
+	case GridSortActivity:
+		sort.SliceStable(branches, func(i, j int) bool {
+			return m.activity[branches[i].Name].After(m.activity[branches[j].Name])
+		})
+	case GridSortChurn:
+		sort.SliceStable(branches, func(i, j int) bool {
+			return m.churn[branches[i].Name] > m.churn[branches[j].Name]
+		})
+	}
+	m.branches = branches
+
+	for i, br := range branches {
+		if br.Name == selected {
+			m.cursor = i
+			return
+		}
+	}
+	if m.cursor >= len(m.branches) && len(m.branches) > 0 {
+		m.cursor = len(m.branches) - 1
+	}
+}
+
+// loadSortData gathers the activity and churn values the sort modes need.
+func (m GridModel) loadSortData() tea.Cmd {
+	mode := m.sortMode
+	branches := m.branches
+	return func() tea.Msg {
+		msg := sortDataMsg{mode: mode, activity: make(map[string]time.Time), churn: make(map[string]int)}
+		switch mode {
+		case GridSortActivity:
+			for _, b := range branches {
+				msg.activity[b.Name] = claude.LastActivity(b.Path)
+			}
+		case GridSortChurn:
+			for name, stats := range branch.GitStatsBatch(branches) {
+				msg.churn[name] = stats.Added + stats.Removed
+			}
+		}
+		return msg
 	}
 }
 
 // Init initializes the grid model.
 func (m GridModel) Init() tea.Cmd {
 	return tea.Batch(
+		// Only the grid handles clicks; other views keep native selection and
+		// wheel scrolling, so they switch the mouse back off
+		tea.EnableMouseCellMotion,
 		m.loadPaneContent,
 		loadContainerStats,
+		loadGridGitStats(m.branches),
+		loadStartTimes(m.branches),
 		checkProxyStatus,
+		loadStaleness(m.branches),
+		loadClaudeCost(m.branches),
+		m.loadClaudeStates(),
+		m.loadSortData(),
 		gridTickCmd(),
 	)
 }
 
+// loadClaudeCost estimates Claude spend per branch from conversation token usage.
+func loadClaudeCost(branches []*branch.Branch) tea.Cmd {
+	return func() tea.Msg {
+		costs := make(map[string]float64)
+		for _, b := range branches {
+			in, out := claude.GetUsage(b.Path)
+			if in > 0 || out > 0 {
+				costs[b.Name] = claude.EstimateCost(in, out, config.InputTokenPrice, config.OutputTokenPrice)
+			}
+		}
+		return claudeCostMsg(costs)
+	}
+}
+
+// loadClaudeStates reads Claude's state for running branches. Which branches
+// are running comes from the last start-time load rather than docker ps.
+func (m GridModel) loadClaudeStates() tea.Cmd {
+	var running []*branch.Branch
+	for _, b := range m.branches {
+		if _, ok := m.startedAt[b.Name]; ok {
+			running = append(running, b)
+		}
+	}
+	return func() tea.Msg {
+		msg := claudeStatesMsg{states: make(map[string]string), reasons: make(map[string]string)}
+		for _, b := range running {
+			status := claude.GetStatus(b.Path)
+			msg.states[b.Name] = status.State
+			if status.LastMsg != "" {
+				msg.reasons[b.Name] = status.LastMsg
+			} else if status.LastTool != "" {
+				msg.reasons[b.Name] = "after " + status.LastTool
+			}
+		}
+		return msg
+	}
+}
+
+// inotifyRefreshRate is how often inotify watches are counted; it walks /proc
+// so it's kept off the per-second tick.
+const inotifyRefreshRate = 15 * time.Second
+
+func loadInotifyUsage() tea.Msg {
+	usage, err := inotify.CurrentUsage()
+	if err != nil {
+		return inotifyUsageMsg{}
+	}
+	return inotifyUsageMsg(usage)
+}
+
+// loadStaleness checks branch age and behind count. Only run on init since
+// it doesn't change meaningfully while the grid is open.
+func loadStaleness(branches []*branch.Branch) tea.Cmd {
+	return func() tea.Msg {
+		stale := make(map[string]string)
+		for _, b := range branches {
+			if desc, isStale := b.Staleness(); isStale {
+				stale[b.Name] = desc
+			}
+		}
+		return stalenessMsg(stale)
+	}
+}
+
 func gridTickCmd() tea.Cmd {
 	return tea.Tick(1*time.Second, func(t time.Time) tea.Msg {
 		return gridTickMsg(t)
@@ -100,12 +324,41 @@ func (m GridModel) loadPaneContent() tea.Msg {
 	content := make(map[string]string)
 	for _, b := range m.branches {
 		if b.IsRunning() && tmux.BranchSessionExists(b.Name) {
-			content[b.Name] = tmux.CapturePaneContent(b.Name, 8)
+			content[b.Name] = tmux.CapturePaneContent(b.Name, 8, config.PaneColors)
 		}
 	}
 	return paneContentMsg(content)
 }
 
+func loadGridGitStats(branches []*branch.Branch) tea.Cmd {
+	return func() tea.Msg {
+		return gridGitStatsMsg(branch.GitStatsBatch(branches))
+	}
+}
+
+// loadStartTimes looks up when each running branch's container started.
+func loadStartTimes(branches []*branch.Branch) tea.Cmd {
+	return func() tea.Msg {
+		started := make(map[string]time.Time)
+		for _, b := range branches {
+			id, err := b.ContainerID()
+			if err != nil || id == "" {
+				continue
+			}
+			if t, err := container.StartedAt(id); err == nil {
+				started[b.Name] = t
+			}
+		}
+		return startTimesMsg(started)
+	}
+}
+
+// loadRunning finds which branches are running with a single docker ps, so
+// rendering needn't ask docker per branch.
+func loadRunning() tea.Msg {
+	return runningMsg(branch.RunningNames())
+}
+
 func loadContainerStats() tea.Msg {
 	stats := make(map[string]ContainerStats)
 	// Get stats for all dark- containers in one call
@@ -144,51 +397,33 @@ func (m GridModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.message = ""
 		m.err = nil
 
-		switch msg.String() {
-		case "q", "ctrl+c":
+		switch m.keys.Action(msg.String()) {
+		case config.KeyQuit:
 			return m, tea.Quit
 
-		case "left":
+		case config.KeyLeft:
 			if m.cursor > 0 {
 				m.cursor--
 			}
 
-		case "right":
+		case config.KeyRight:
 			if m.cursor < len(m.branches)-1 {
 				m.cursor++
 			}
 
-		case "up":
+		case config.KeyUp:
 			cols := m.numCols()
 			if m.cursor >= cols {
 				m.cursor -= cols
 			}
 
-		case "down":
+		case config.KeyDown:
 			cols := m.numCols()
 			if m.cursor+cols < len(m.branches) {
 				m.cursor += cols
 			}
 
-		case "enter":
-			// Open Claude for selected branch (same as 'c')
-			if len(m.branches) > 0 && m.cursor < len(m.branches) {
-				b := m.branches[m.cursor]
-				if b.IsRunning() {
-					containerID, err := b.ContainerID()
-					if err != nil {
-						m.message = fmt.Sprintf("Error: %v", err)
-						return m, nil
-					}
-					if err := tmux.OpenClaude(b.Name, containerID); err != nil {
-						m.message = fmt.Sprintf("Error: %v", err)
-					}
-				} else {
-					m.message = fmt.Sprintf("%s is stopped - press 's' to start", b.Name)
-				}
-			}
-
-		case "t":
+		case config.KeyTerminal:
 			// Open terminal for selected branch
 			if len(m.branches) > 0 && m.cursor < len(m.branches) {
 				b := m.branches[m.cursor]
@@ -206,30 +441,22 @@ func (m GridModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 
-		case "c":
-			// Open Claude for selected branch
-			if len(m.branches) > 0 && m.cursor < len(m.branches) {
-				b := m.branches[m.cursor]
-				if b.IsRunning() {
-					containerID, err := b.ContainerID()
-					if err != nil {
-						m.message = fmt.Sprintf("Error: %v", err)
-						return m, nil
-					}
-					if err := tmux.OpenClaude(b.Name, containerID); err != nil {
-						m.message = fmt.Sprintf("Error: %v", err)
-					}
-				} else {
-					m.message = fmt.Sprintf("%s is stopped - press 's' to start", b.Name)
-				}
-			}
+		case config.KeyClaude:
+			// Open Claude for selected branch (enter or 'c' by default)
+			return m, m.openSelectedClaude()
 
-		case "s":
+		case config.KeyStart:
 			// Start selected branch
 			if len(m.branches) > 0 && m.cursor < len(m.branches) {
 				b := m.branches[m.cursor]
 				if b.IsRunning() {
 					m.message = fmt.Sprintf("%s is already running", b.Name)
+				} else if m.memoryHeadroom() < 1 {
+					m.message = m.memoryHeadroomMessage()
+				} else if m.overcommitWarning(1) != "" {
+					m.confirmStart = []*branch.Branch{b}
+					m.inputMode = GridInputConfirmStart
+					return m, nil
 				} else {
 					globalPendingBranches[b.Name] = &PendingBranch{Name: b.Name, Status: "starting container"}
 					m.loading = true
@@ -237,7 +464,46 @@ func (m GridModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 
-		case "k":
+		case config.KeyStartAll:
+			// Start stopped branches, up to the suggested max instances
+			running := 0
+			var stopped []*branch.Branch
+			for _, b := range m.branches {
+				if b.IsRunning() {
+					running++
+				} else if _, pending := globalPendingBranches[b.Name]; !pending {
+					stopped = append(stopped, b)
+				}
+			}
+			if len(stopped) == 0 {
+				m.message = "No stopped branches"
+				return m, nil
+			}
+			headroom := config.SuggestMaxInstances() - running
+			if headroom <= 0 {
+				m.message = fmt.Sprintf("Already at suggested max (%d running)", running)
+				return m, nil
+			}
+			if memHeadroom := m.memoryHeadroom(); memHeadroom < headroom {
+				headroom = memHeadroom
+			}
+			if headroom <= 0 {
+				m.message = m.memoryHeadroomMessage()
+				return m, nil
+			}
+			if len(stopped) > headroom {
+				stopped = stopped[:headroom]
+			}
+			var cmds []tea.Cmd
+			for _, b := range stopped {
+				globalPendingBranches[b.Name] = &PendingBranch{Name: b.Name, Status: "starting container"}
+				cmds = append(cmds, m.startBranch(b))
+			}
+			m.message = fmt.Sprintf("Starting %d branches", len(stopped))
+			m.loading = true
+			return m, tea.Batch(cmds...)
+
+		case config.KeyKill:
 			// Kill (stop) selected branch
 			if len(m.branches) > 0 && m.cursor < len(m.branches) {
 				b := m.branches[m.cursor]
@@ -250,20 +516,30 @@ func (m GridModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 
-		case "n":
+		case config.KeyKillAll:
+			// Kill (stop) every running branch, after confirmation
+			for _, b := range m.branches {
+				if b.IsRunning() {
+					m.inputMode = GridInputConfirmStopAll
+					return m, nil
+				}
+			}
+			m.message = "No running branches"
+
+		case config.KeyNewBranch:
 			// New branch
 			m.inputMode = GridInputNewBranch
 			m.inputText = ""
 			return m, nil
 
-		case "x":
+		case config.KeyDelete:
 			// Delete branch
 			if len(m.branches) > 0 {
 				m.inputMode = GridInputConfirmDelete
 				return m, nil
 			}
 
-		case "e":
+		case config.KeyEditor:
 			// Open VS Code (editor)
 			if len(m.branches) > 0 && m.cursor < len(m.branches) {
 				b := m.branches[m.cursor]
@@ -274,34 +550,124 @@ func (m GridModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, m.openCode(b)
 			}
 
-		case "m":
+		case config.KeyMatter:
 			// Open Matter
 			if len(m.branches) > 0 && m.cursor < len(m.branches) {
 				b := m.branches[m.cursor]
-				url := fmt.Sprintf("dark-packages.%s.dlio.localhost:%d/ping", b.Name, config.ProxyPort)
+				url := fmt.Sprintf("dark-packages.%s.dlio.localhost:%d/ping", b.Name, config.GetProxyPort())
 				openInBrowser(url)
 				m.message = "Opened Matter"
 			}
 
-		case "d":
-			// Open diff (gitk)
+		case config.KeyCopyURL:
+			// Copy Matter URL
+			if len(m.branches) > 0 && m.cursor < len(m.branches) {
+				b := m.branches[m.cursor]
+				url := fmt.Sprintf("http://dark-packages.%s.dlio.localhost:%d/ping", b.Name, config.GetProxyPort())
+				via := copyToClipboard(url)
+				m.message = fmt.Sprintf("Copied %s (via %s)", url, via)
+			}
+
+		case config.KeyCopyID:
+			// Copy container ID
+			if len(m.branches) > 0 && m.cursor < len(m.branches) {
+				b := m.branches[m.cursor]
+				containerID, err := b.ContainerID()
+				if err != nil {
+					m.message = fmt.Sprintf("%s has no container", b.Name)
+					return m, nil
+				}
+				via := copyToClipboard(containerID)
+				m.message = fmt.Sprintf("Copied container ID %s (via %s)", containerID, via)
+			}
+
+		case config.KeyDiff:
+			// View diff in the TUI
+			if len(m.branches) > 0 && m.cursor < len(m.branches) {
+				b := m.branches[m.cursor]
+				diff := NewDiffViewerModel(b)
+				return diff, tea.Batch(tea.DisableMouse, diff.Init())
+			}
+
+		case config.KeyDiffExternal:
+			// Open diff in gitk
 			if len(m.branches) > 0 && m.cursor < len(m.branches) {
 				b := m.branches[m.cursor]
 				return m, m.openDiff(b)
 			}
 
-		case "l":
+		case config.KeyLogs:
 			// View logs
 			if len(m.branches) > 0 && m.cursor < len(m.branches) {
 				b := m.branches[m.cursor]
 				logs := NewLogViewerModel(b)
-				return logs, logs.Init()
+				return logs, tea.Batch(tea.DisableMouse, logs.Init())
+			}
+
+		case config.KeyDiskUsage:
+			// Measure the clone and volumes in the background
+			if len(m.branches) > 0 && m.cursor < len(m.branches) {
+				b := m.branches[m.cursor]
+				m.message = fmt.Sprintf("Measuring disk usage of %s...", b.Name)
+				return m, func() tea.Msg {
+					usage, err := branch.DiskUsage(b)
+					return diskUsageMsg{name: b.Name, usage: usage, err: err}
+				}
+			}
+
+		case config.KeyListMode:
+			// Toggle between the grid and one row per branch
+			m.listMode = !m.listMode
+			m.savePrefs()
+			switch {
+			case m.listMode:
+				m.message = "Layout: list"
+			case m.inListMode():
+				m.message = "Layout: grid (terminal too narrow, showing list)"
+			default:
+				m.message = "Layout: grid"
 			}
 
-		case "?":
-			return NewHelpModel(), nil
+		case config.KeySort:
+			// Cycle sort order
+			m.sortMode = (m.sortMode + 1) % GridSortMode(len(gridSortNames))
+			m.setBranches(m.branches)
+			m.savePrefs()
+			m.message = fmt.Sprintf("Sort: %s", m.sortMode)
+			return m, m.loadSortData()
+
+		case config.KeySearch:
+			// Jump to a branch by typing part of its name
+			m.inputMode = GridInputSearch
+			m.inputText = ""
+			m.searchOrigin = m.cursor
+			return m, nil
+
+		case config.KeyHelp:
+			return NewHelpModel(), tea.DisableMouse
 		}
 
+	case tea.MouseMsg:
+		if msg.Action != tea.MouseActionPress || msg.Button != tea.MouseButtonLeft || m.inputMode != GridInputNone {
+			return m, nil
+		}
+		idx := m.cellAt(msg.X, msg.Y)
+		if idx < 0 || idx >= len(m.branches) {
+			return m, nil
+		}
+		m.message = ""
+		m.err = nil
+		// A second click on the same cell shortly after the first opens Claude
+		doubleClick := idx == m.lastClickIdx && time.Since(m.lastClickAt) < doubleClickWindow
+		m.cursor = idx
+		m.lastClickIdx = idx
+		m.lastClickAt = time.Now()
+		if doubleClick {
+			m.lastClickAt = time.Time{}
+			return m, m.openSelectedClaude()
+		}
+		return m, nil
+
 	case paneContentMsg:
 		if msg != nil {
 			m.paneContent = msg
@@ -314,18 +680,113 @@ func (m GridModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case containerEventMsg:
+		// A container started or died - refresh now rather than on the next tick
+		m.setBranches(branch.GetManagedBranches())
+		return m, tea.Batch(loadRunning, m.loadPaneContent, loadContainerStats, loadStartTimes(m.branches))
+
+	case stalenessMsg:
+		m.staleness = msg
+		return m, nil
+
+	case claudeCostMsg:
+		m.claudeCost = msg
+		return m, nil
+
+	case diskUsageMsg:
+		if msg.err != nil {
+			m.message = fmt.Sprintf("%s: clone %s (volume sizes unavailable: %v)", msg.name, container.FormatBytes(msg.usage.Worktree), msg.err)
+			return m, nil
+		}
+		m.message = fmt.Sprintf("%s: %s (clone %s, volumes %s)", msg.name, container.FormatBytes(msg.usage.Total()),
+			container.FormatBytes(msg.usage.Worktree), container.FormatBytes(msg.usage.Volumes))
+		return m, nil
+
+	case gridGitStatsMsg:
+		m.gitStats = msg
+		return m, nil
+
+	case startTimesMsg:
+		m.startedAt = msg
+		return m, nil
+
+	case runningMsg:
+		m.running = msg
+		return m, nil
+
+	case uptimeStoppedMsg:
+		m.message = fmt.Sprintf("Stopped %s: up longer than %s (DARK_MULTI_MAX_UPTIME)", strings.Join(msg, ", "), formatDuration(config.MaxUptime))
+		m.setBranches(branch.GetManagedBranches())
+		return m, tea.Batch(loadRunning, m.loadPaneContent)
+
+	case inotifyUsageMsg:
+		m.inotifyUsage = inotify.Usage(msg)
+		return m, nil
+
+	case claudeStatesMsg:
+		if config.Notifications {
+			names := waitingTransitions(m.claudeStates, msg.states)
+			if !notifyWaiting(names, msg.reasons) {
+				// No desktop notifier; say it in the status line instead
+				m.message = fmt.Sprintf("Claude needs input: %s", strings.Join(names, ", "))
+			}
+		}
+		m.claudeStates = msg.states
+		return m, nil
+
+	case sortDataMsg:
+		// Data loaded for a sort that's since been switched away from is stale
+		if msg.mode != m.sortMode {
+			return m, nil
+		}
+		switch msg.mode {
+		case GridSortActivity:
+			m.activity = msg.activity
+		case GridSortChurn:
+			m.churn = msg.churn
+		}
+		m.setBranches(m.branches)
+		return m, nil
+
 	case proxyStatusMsg:
 		m.proxyRunning = bool(msg)
 		return m, nil
 
 	case gridTickMsg:
 		// Refresh branches and content periodically
-		m.branches = branch.GetManagedBranches()
-		if m.cursor >= len(m.branches) && len(m.branches) > 0 {
-			m.cursor = len(m.branches) - 1
-		}
+		m.setBranches(branch.GetManagedBranches())
 		// Note: Don't clean up globalPendingBranches here - let branchStartedMsg handle it
-		return m, tea.Batch(m.loadPaneContent, loadContainerStats, gridTickCmd())
+		cmds := []tea.Cmd{gridTickCmd(), loadRunning}
+		// Pane capture, docker stats and Claude state/cost read per branch, so
+		// they run on the slower refresh interval rather than every tick
+		if time.Since(m.heavyRefreshed) >= m.refreshInterval {
+			m.heavyRefreshed = time.Now()
+			cmds = append(cmds, m.loadPaneContent, loadContainerStats, loadGridGitStats(m.branches), loadStartTimes(m.branches),
+				loadClaudeCost(m.branches), m.loadClaudeStates())
+		}
+		if m.sortMode == GridSortActivity || m.sortMode == GridSortChurn {
+			cmds = append(cmds, m.loadSortData())
+		}
+		if runtime.GOOS == "linux" && time.Since(m.inotifyChecked) > inotifyRefreshRate {
+			m.inotifyChecked = time.Now()
+			cmds = append(cmds, loadInotifyUsage)
+		}
+		return m, tea.Batch(cmds...)
+
+	case claudeReadyMsg:
+		if msg.missing {
+			globalPendingBranches[msg.name] = &PendingBranch{Name: msg.name, Status: claudeInstallStatus}
+			return m, installClaude(msg.name, msg.containerID)
+		}
+		if pending, ok := globalPendingBranches[msg.name]; ok && pending.Status == claudeInstallStatus {
+			delete(globalPendingBranches, msg.name)
+		}
+		if msg.err != nil {
+			m.message = fmt.Sprintf("Error: %v", msg.err)
+		} else if err := tmux.OpenClaude(msg.name, msg.containerID); err != nil {
+			m.message = fmt.Sprintf("Error: %v", err)
+		}
+		return m, nil
 
 	case createStepMsg:
 		if pending, ok := globalPendingBranches[msg.name]; ok {
@@ -336,20 +797,20 @@ func (m GridModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case branchStartedMsg:
 		delete(globalPendingBranches, msg.name)
 		m.loading = false
-		m.branches = branch.GetManagedBranches()
-		return m, m.loadPaneContent
+		m.setBranches(branch.GetManagedBranches())
+		return m, tea.Batch(loadRunning, m.loadPaneContent)
 
 	case operationDoneMsg:
 		m.message = msg.message
 		m.loading = false
-		m.branches = branch.GetManagedBranches()
+		m.setBranches(branch.GetManagedBranches())
 		// Clean up any pending branches that are now running
 		for _, b := range m.branches {
 			if b.IsRunning() {
 				delete(globalPendingBranches, b.Name)
 			}
 		}
-		return m, m.loadPaneContent
+		return m, tea.Batch(loadRunning, m.loadPaneContent)
 
 	case operationErrMsg:
 		for name := range globalPendingBranches {
@@ -400,10 +861,7 @@ func (m GridModel) handleInputMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, nil
 
 		default:
-			key := msg.String()
-			if len(key) == 1 && isValidBranchChar(key[0]) {
-				m.inputText += key
-			}
+			m.inputText = appendBranchInput(m.inputText, msg)
 			return m, nil
 		}
 
@@ -425,11 +883,248 @@ func (m GridModel) handleInputMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.message = "Cancelled"
 			return m, nil
 		}
+
+	case GridInputConfirmStopAll:
+		switch msg.String() {
+		case "y", "Y":
+			m.inputMode = GridInputNone
+			m.loading = true
+			m.message = "Stopping all branches..."
+			return m, m.stopAll()
+
+		case "n", "N", "esc":
+			m.inputMode = GridInputNone
+			m.message = "Cancelled"
+			return m, nil
+		}
+
+	case GridInputConfirmStart:
+		switch msg.String() {
+		case "y", "Y":
+			m.inputMode = GridInputNone
+			var cmds []tea.Cmd
+			for _, b := range m.confirmStart {
+				globalPendingBranches[b.Name] = &PendingBranch{Name: b.Name, Status: "starting container"}
+				cmds = append(cmds, m.startBranch(b))
+			}
+			m.confirmStart = nil
+			m.loading = true
+			return m, tea.Batch(cmds...)
+
+		case "n", "N", "esc":
+			m.inputMode = GridInputNone
+			m.confirmStart = nil
+			m.message = "Cancelled"
+			return m, nil
+		}
+
+	case GridInputSearch:
+		switch msg.String() {
+		case "enter":
+			m.inputMode = GridInputNone
+			if m.inputText != "" && m.searchMatch(m.inputText) < 0 {
+				m.cursor = m.searchOrigin
+				m.message = fmt.Sprintf("No branch matching '%s'", m.inputText)
+			}
+			m.inputText = ""
+			return m, nil
+
+		case "esc":
+			m.inputMode = GridInputNone
+			m.inputText = ""
+			m.cursor = m.searchOrigin
+			return m, nil
+
+		case "backspace":
+			if len(m.inputText) > 0 {
+				m.inputText = m.inputText[:len(m.inputText)-1]
+			}
+
+		default:
+			m.inputText = appendBranchInput(m.inputText, msg)
+		}
+
+		// Move the cursor as the query changes
+		if idx := m.searchMatch(m.inputText); idx >= 0 {
+			m.cursor = idx
+		} else if m.inputText == "" {
+			m.cursor = m.searchOrigin
+		}
+		return m, nil
 	}
 
 	return m, nil
 }
 
+// openSelectedClaude opens Claude for the branch under the cursor. A new
+// session first checks claude is installed in the container, which can mean
+// a slow reinstall, so the session opens when claudeReadyMsg arrives.
+func (m *GridModel) openSelectedClaude() tea.Cmd {
+	if len(m.branches) == 0 || m.cursor >= len(m.branches) {
+		return nil
+	}
+	b := m.branches[m.cursor]
+	if !b.IsRunning() {
+		m.message = fmt.Sprintf("%s is stopped - press 's' to start", b.Name)
+		return nil
+	}
+	if _, pending := globalPendingBranches[b.Name]; pending {
+		return nil
+	}
+	containerID, err := b.ContainerID()
+	if err != nil {
+		m.message = fmt.Sprintf("Error: %v", err)
+		return nil
+	}
+	if !tmux.ClaudeSessionExists(b.Name) {
+		return checkClaude(b.Name, containerID)
+	}
+	if err := tmux.OpenClaude(b.Name, containerID); err != nil {
+		m.message = fmt.Sprintf("Error: %v", err)
+	}
+	return nil
+}
+
+// claudeInstallStatus is the pending status shown while claude is reinstalled.
+const claudeInstallStatus = "installing claude…"
+
+// checkClaude looks for claude in a branch's container.
+func checkClaude(name, containerID string) tea.Cmd {
+	return func() tea.Msg {
+		return claudeReadyMsg{name: name, containerID: containerID, missing: !tmux.ClaudeInstalled(containerID)}
+	}
+}
+
+// installClaude reinstalls claude in a branch's container.
+func installClaude(name, containerID string) tea.Cmd {
+	return func() tea.Msg {
+		return claudeReadyMsg{name: name, containerID: containerID, err: tmux.InstallClaude(containerID)}
+	}
+}
+
+// gridSize returns the terminal size used for layout, with fallbacks
+// for before the first WindowSizeMsg arrives.
+func (m GridModel) gridSize() (width, height int) {
+	width = m.width
+	if width < 40 {
+		width = 120
+	}
+	height = m.height
+	if height < 10 {
+		height = 40
+	}
+	return width, height
+}
+
+// cellAt maps screen coordinates to a cell index, or -1 outside the grid.
+// Mirrors the layout in View: 2 rows, columns splitting the width evenly.
+func (m GridModel) cellAt(x, y int) int {
+	if m.inListMode() {
+		if y < 0 || y >= m.listRows() {
+			return -1
+		}
+		return m.listOffset() + y
+	}
+
+	width, height := m.gridSize()
+	cellHeight := (height - 5) / 2
+	if cellHeight <= 0 || y < 0 || y >= 2*cellHeight {
+		return -1
+	}
+	row := y / cellHeight
+
+	cols := m.numCols()
+	remainingWidth := width
+	left := 0
+	for col := 0; col < cols; col++ {
+		cellWidth := remainingWidth / (cols - col)
+		remainingWidth -= cellWidth
+		if x >= left && x < left+cellWidth {
+			return row*cols + col
+		}
+		left += cellWidth
+	}
+	return -1
+}
+
+// searchMatch returns the index of the first branch whose name contains query
+// (case-insensitive), or -1 if none match.
+func (m GridModel) searchMatch(query string) int {
+	if query == "" {
+		return -1
+	}
+	query = strings.ToLower(query)
+	for i, br := range m.branches {
+		if strings.Contains(strings.ToLower(br.Name), query) {
+			return i
+		}
+	}
+	return -1
+}
+
+// overcommitWarning returns a warning if starting n more branches would push past
+// the suggested max instances, or "" if there's headroom.
+func (m GridModel) overcommitWarning(n int) string {
+	running := 0
+	for _, br := range m.branches {
+		if m.running[br.Name] {
+			running++
+		}
+	}
+	maxSuggested := config.SuggestMaxInstances()
+	total := running + n
+	if total <= maxSuggested {
+		return ""
+	}
+
+	cpuCores, ramGB := config.GetSystemResources()
+	return fmt.Sprintf("This would run %d instances (suggested max %d): ~%d/%d cores, ~%dGB/%dGB RAM",
+		total, maxSuggested, total*config.CPUPerInstance, cpuCores, total*config.RAMPerInstanceGB, ramGB)
+}
+
+// totalContainerMemMB sums memory use across containers from the last docker stats.
+func (m GridModel) totalContainerMemMB() float64 {
+	var total float64
+	for _, stats := range m.containerStats {
+		total += parseMemoryMB(stats.Memory)
+	}
+	return total
+}
+
+// memoryHeadroom returns how many more containers can start before projected
+// memory use passes config.MemoryHeadroomPct of host RAM. Pending starts count
+// as a full instance since docker stats doesn't see them yet.
+func (m GridModel) memoryHeadroom() int {
+	if config.MemoryHeadroomPct <= 0 {
+		return math.MaxInt
+	}
+	_, ramGB := config.GetSystemResources()
+	limitMB := float64(ramGB) * 1024 * float64(config.MemoryHeadroomPct) / 100
+	perInstanceMB := float64(config.RAMPerInstanceGB) * 1024
+	usedMB := m.totalContainerMemMB() + float64(len(globalPendingBranches))*perInstanceMB
+	return max(0, int((limitMB-usedMB)/perInstanceMB))
+}
+
+// memoryHeadroomMessage explains why a start was refused by memoryHeadroom.
+func (m GridModel) memoryHeadroomMessage() string {
+	_, ramGB := config.GetSystemResources()
+	return fmt.Sprintf("Not starting: containers use %.1fGB, another ~%dGB would pass %d%% of %dGB RAM",
+		m.totalContainerMemMB()/1024, config.RAMPerInstanceGB, config.MemoryHeadroomPct, ramGB)
+}
+
+// parseMemoryMB parses docker stats memory like "1.2GiB" or "500MiB".
+func parseMemoryMB(mem string) float64 {
+	var memVal float64
+	if strings.HasSuffix(mem, "GiB") {
+		fmt.Sscanf(strings.TrimSuffix(mem, "GiB"), "%f", &memVal)
+		return memVal * 1024
+	} else if strings.HasSuffix(mem, "MiB") {
+		fmt.Sscanf(strings.TrimSuffix(mem, "MiB"), "%f", &memVal)
+		return memVal
+	}
+	return 0
+}
+
 // filteredPendingBranches returns pending branches that don't overlap with existing branches
 func (m GridModel) filteredPendingBranches() []*PendingBranch {
 	var result []*PendingBranch
@@ -448,7 +1143,17 @@ func (m GridModel) filteredPendingBranches() []*PendingBranch {
 	return result
 }
 
+// numCols returns the columns used for layout and navigation: one in list
+// mode, otherwise the grid's.
 func (m GridModel) numCols() int {
+	if m.inListMode() {
+		return 1
+	}
+	return m.gridCols()
+}
+
+// gridCols returns how many columns the grid needs to fit every branch in 2 rows.
+func (m GridModel) gridCols() int {
 	pending := m.filteredPendingBranches()
 	n := len(m.branches) + len(pending)
 	if n == 0 {
@@ -489,6 +1194,32 @@ func (m GridModel) View() string {
 		return b.String()
 	}
 
+	if m.inputMode == GridInputConfirmStopAll {
+		b.WriteString(titleStyle.Render("STOP ALL BRANCHES"))
+		b.WriteString("\n\n")
+		var names []string
+		for _, br := range m.branches {
+			if m.running[br.Name] {
+				names = append(names, br.Name)
+			}
+		}
+		b.WriteString(fmt.Sprintf("Stop %s? [y/n]", strings.Join(names, ", ")))
+		return b.String()
+	}
+
+	if m.inputMode == GridInputConfirmStart {
+		b.WriteString(titleStyle.Render("START BRANCH"))
+		b.WriteString("\n\n")
+		names := make([]string, len(m.confirmStart))
+		for i, br := range m.confirmStart {
+			names[i] = br.Name
+		}
+		b.WriteString(errorStyle.Render("⚠ " + m.overcommitWarning(len(m.confirmStart))))
+		b.WriteString("\n")
+		b.WriteString(fmt.Sprintf("Start %s anyway? [y/n]", strings.Join(names, ", ")))
+		return b.String()
+	}
+
 	if totalBranches == 0 {
 		b.WriteString(titleStyle.Render("DARK MULTI"))
 		b.WriteString("\n\n")
@@ -500,17 +1231,43 @@ func (m GridModel) View() string {
 		return b.String()
 	}
 
-	// Calculate cell dimensions
-	cols := m.numCols()
-	width := m.width
-	if width < 40 {
-		width = 120
+	if m.inListMode() {
+		b.WriteString(m.renderList(pendingBranches))
+	} else {
+		b.WriteString(m.renderGrid(pendingBranches))
 	}
-	height := m.height
-	if height < 10 {
-		height = 40
+
+	// Status bar
+	b.WriteString("\n")
+	b.WriteString(m.renderStatusBar())
+	b.WriteString("\n")
+
+	// Message or help
+	if m.inputMode == GridInputSearch {
+		b.WriteString(selectedStyle.Render("/"))
+		b.WriteString(m.inputText)
+		b.WriteString("█")
+		if m.inputText != "" && m.searchMatch(m.inputText) < 0 {
+			b.WriteString(errorStyle.Render("  no match"))
+		}
+		b.WriteString(helpStyle.Render("  [enter] jump  [esc] cancel"))
+	} else if m.err != nil {
+		b.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", m.err)))
+	} else if m.message != "" {
+		b.WriteString(m.message)
+	} else {
+		b.WriteString(helpStyle.Render("[n]ew [x]del [s]tart [k]ill [c]laude [t]erm [e]ditor [l]ogs [d]iff [m]atter [/]search s[o]rt [?]help [q]uit"))
 	}
 
+	return b.String()
+}
+
+// renderGrid draws the branches as cells in two rows.
+func (m GridModel) renderGrid(pendingBranches []*PendingBranch) string {
+	// Calculate cell dimensions
+	cols := m.numCols()
+	width, height := m.gridSize()
+
 	// Reserve 5 lines for newline, status bar, newline, and help/message
 	cellHeight := (height - 5) / 2
 
@@ -542,30 +1299,14 @@ func (m GridModel) View() string {
 		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, cells...))
 	}
 
-	b.WriteString(lipgloss.JoinVertical(lipgloss.Left, rows...))
-
-	// Status bar
-	b.WriteString("\n")
-	b.WriteString(m.renderStatusBar())
-	b.WriteString("\n")
-
-	// Message or help
-	if m.err != nil {
-		b.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", m.err)))
-	} else if m.message != "" {
-		b.WriteString(m.message)
-	} else {
-		b.WriteString(helpStyle.Render("[n]ew [x]del [s]tart [k]ill [c]laude [t]erm [e]ditor [l]ogs [d]iff [m]atter [?]help [q]uit"))
-	}
-
-	return b.String()
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
 }
 
 func (m GridModel) renderStatusBar() string {
 	cpuCores, ramGB := config.GetSystemResources()
 	running := 0
 	for _, br := range m.branches {
-		if br.IsRunning() {
+		if m.running[br.Name] {
 			running++
 		}
 	}
@@ -578,16 +1319,7 @@ func (m GridModel) renderStatusBar() string {
 		var cpu float64
 		fmt.Sscanf(strings.TrimSuffix(stats.CPU, "%"), "%f", &cpu)
 		totalCPU += cpu
-		// Parse memory like "1.2GiB" or "500MiB"
-		mem := stats.Memory
-		var memVal float64
-		if strings.HasSuffix(mem, "GiB") {
-			fmt.Sscanf(strings.TrimSuffix(mem, "GiB"), "%f", &memVal)
-			totalMemMB += memVal * 1024
-		} else if strings.HasSuffix(mem, "MiB") {
-			fmt.Sscanf(strings.TrimSuffix(mem, "MiB"), "%f", &memVal)
-			totalMemMB += memVal
-		}
+		totalMemMB += parseMemoryMB(stats.Memory)
 	}
 
 	maxSuggested := config.SuggestMaxInstances()
@@ -606,8 +1338,15 @@ func (m GridModel) renderStatusBar() string {
 		memStr = fmt.Sprintf("%.1fGB", totalMemMB/1024)
 	}
 
-	return statusBarStyle.Render(fmt.Sprintf("%d cores, %dGB  •  %d/%d running (%.0f%% CPU, %s/%.0f%% RAM)  •  proxy %s",
-		cpuCores, ramGB, running, maxSuggested, hostCpuPct, memStr, hostMemPct, proxyStatus))
+	bar := statusBarStyle.Render(fmt.Sprintf("%d cores, %dGB  •  %d/%d running (%.0f%% CPU, %s/%.0f%% RAM)  •  proxy %s  •  sort: %s",
+		cpuCores, ramGB, running, maxSuggested, hostCpuPct, memStr, hostMemPct, proxyStatus, m.sortMode))
+
+	// Running out of watches silently breaks hot reload in containers
+	if m.inotifyUsage.NearLimit() {
+		bar += "  " + errorStyle.Render(fmt.Sprintf("⚠ inotify %d%% (%d/%d) - run 'multi setup-inotify'",
+			m.inotifyUsage.Percent(), m.inotifyUsage.Watches, m.inotifyUsage.MaxWatches))
+	}
+	return bar
 }
 
 func (m GridModel) renderCell(idx int, width, height int) string {
@@ -660,19 +1399,41 @@ func (m GridModel) renderCell(idx int, width, height int) string {
 	// Header with status icon and branch name
 	var header string
 	statusIcon := stoppedStyle.Render("○")
-	if br.IsRunning() {
+	if m.running[br.Name] {
 		statusIcon = runningStyle.Render("●")
 	}
 	header = statusIcon + " " + cellHeaderStyle.Render(br.Name)
 
+	// Claude activity indicator
+	switch m.claudeStates[br.Name] {
+	case "waiting":
+		header += " 💬"
+	case "working":
+		header += runningStyle.Render(" ⚡")
+	}
+
+	// How long the container has been up, recomputed on every tick
+	if started, ok := m.startedAt[br.Name]; ok {
+		header += helpStyle.Render(", up " + formatDuration(time.Since(started)))
+	}
+
 	// Add git stats (commits ahead, lines changed)
-	commits, added, removed := br.GitStats()
-	if commits > 0 || added > 0 || removed > 0 {
-		header += helpStyle.Render(fmt.Sprintf(", git: %dc +%d/-%d", commits, added, removed))
+	if gs := m.gitStats[br.Name]; gs.Commits > 0 || gs.Added > 0 || gs.Removed > 0 {
+		header += helpStyle.Render(fmt.Sprintf(", git: %dc +%d/-%d", gs.Commits, gs.Added, gs.Removed))
+	}
+
+	// Estimated Claude spend for the latest conversation
+	if cost, ok := m.claudeCost[br.Name]; ok {
+		header += helpStyle.Render(fmt.Sprintf(", ~$%.2f", cost))
+	}
+
+	// Flag branches that are old or far behind main
+	if desc, ok := m.staleness[br.Name]; ok {
+		header += " " + modifiedStyle.Render("⏳ "+desc)
 	}
 
 	// Add CPU/RAM stats if running
-	if stats, ok := m.containerStats[br.Name]; ok && br.IsRunning() {
+	if stats, ok := m.containerStats[br.Name]; ok && m.running[br.Name] {
 		cpuCores, ramGB := config.GetSystemResources()
 		// Convert CPU percentage to % of total host CPU
 		var cpuPct float64
@@ -698,7 +1459,7 @@ func (m GridModel) renderCell(idx int, width, height int) string {
 
 	// Content
 	var content string
-	if br.IsRunning() {
+	if m.running[br.Name] {
 		if !tmux.BranchSessionExists(br.Name) {
 			content = stoppedStyle.Render("[ready - press 'c' for Claude]")
 		} else if pane, ok := m.paneContent[br.Name]; ok && pane != "" {
@@ -708,9 +1469,7 @@ func (m GridModel) renderCell(idx int, width, height int) string {
 				lines = lines[len(lines)-maxLines:]
 			}
 			for i, line := range lines {
-				if len(line) > innerWidth {
-					lines[i] = line[:innerWidth-1] + "…"
-				}
+				lines[i] = truncateANSI(line, innerWidth)
 			}
 			content = strings.Join(lines, "\n")
 		} else {
@@ -784,6 +1543,16 @@ func (m GridModel) stopBranch(b *branch.Branch) tea.Cmd {
 	}
 }
 
+func (m GridModel) stopAll() tea.Cmd {
+	return func() tea.Msg {
+		stopped, err := stopAllFull()
+		if err != nil {
+			return operationErrMsg{err}
+		}
+		return operationDoneMsg{fmt.Sprintf("Stopped %d branches", stopped)}
+	}
+}
+
 func (m GridModel) openCode(b *branch.Branch) tea.Cmd {
 	return func() tea.Msg {
 		if err := openVSCode(b); err != nil {
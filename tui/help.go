@@ -1,10 +1,13 @@
 package tui
 
 import (
+	"fmt"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+
+	"github.com/darklang/dark-multi/config"
 )
 
 var (
@@ -15,11 +18,17 @@ var (
 type HelpModel struct {
 	width  int
 	height int
+	keys   config.Keybindings
 }
 
 // NewHelpModel creates a help screen.
 func NewHelpModel() HelpModel {
-	return HelpModel{}
+	return HelpModel{keys: config.LoadKeybindings()}
+}
+
+// keyLine renders a help line for an action using its configured keys.
+func (m HelpModel) keyLine(desc, action string) string {
+	return fmt.Sprintf("  %-11s %s\n", strings.Join(m.keys.Keys(action), "/"), desc)
 }
 
 // Init initializes help model.
@@ -58,23 +67,33 @@ func (m HelpModel) View() string {
 
 	b.WriteString(sectionStyle.Render("Branch Actions"))
 	b.WriteString("\n")
-	b.WriteString("  n           New branch (prompts for name)\n")
-	b.WriteString("  x           Delete branch (with confirmation)\n")
-	b.WriteString("  s           Start branch\n")
-	b.WriteString("  k           Kill (stop) branch\n")
-	b.WriteString("  c           Open Claude\n")
-	b.WriteString("  t           Open terminal (bash)\n")
-	b.WriteString("  e           Open VS Code (editor)\n")
-	b.WriteString("  d           Diff (open gitk)\n")
-	b.WriteString("  m           Open Matter (dark-packages canvas)\n")
-	b.WriteString("  l           View logs\n")
+	b.WriteString(m.keyLine("New branch (prompts for name)", config.KeyNewBranch))
+	b.WriteString(m.keyLine("Delete branch (with confirmation)", config.KeyDelete))
+	b.WriteString(m.keyLine("Start branch", config.KeyStart))
+	b.WriteString(m.keyLine("Start all stopped branches (up to suggested max)", config.KeyStartAll))
+	b.WriteString(m.keyLine("Kill (stop) branch", config.KeyKill))
+	b.WriteString(m.keyLine("Kill (stop) all branches (with confirmation)", config.KeyKillAll))
+	b.WriteString(m.keyLine("Open Claude", config.KeyClaude))
+	b.WriteString(m.keyLine("Open terminal (bash)", config.KeyTerminal))
+	b.WriteString(m.keyLine("Open VS Code (editor)", config.KeyEditor))
+	b.WriteString(m.keyLine("Diff (in-terminal viewer)", config.KeyDiff))
+	b.WriteString(m.keyLine("Diff (open gitk)", config.KeyDiffExternal))
+	b.WriteString(m.keyLine("Open Matter (dark-packages canvas)", config.KeyMatter))
+	b.WriteString(m.keyLine("View logs", config.KeyLogs))
+	b.WriteString(m.keyLine("Copy Matter URL", config.KeyCopyURL))
+	b.WriteString(m.keyLine("Copy container ID", config.KeyCopyID))
+	b.WriteString(m.keyLine("Show disk usage (clone + volumes)", config.KeyDiskUsage))
 	b.WriteString("\n")
 
 	b.WriteString(sectionStyle.Render("Grid View"))
 	b.WriteString("\n")
 	b.WriteString("  arrows      Navigate branches\n")
-	b.WriteString("  enter/c     Open Claude\n")
-	b.WriteString("  g           Switch to grid view\n")
+	b.WriteString("  click       Select branch (double-click opens Claude)\n")
+	b.WriteString(m.keyLine("Search: jump to branch by name", config.KeySearch))
+	b.WriteString(m.keyLine("Cycle sort: name, status, activity, churn", config.KeySort))
+	b.WriteString(m.keyLine("Toggle list layout (automatic in narrow terminals)", config.KeyListMode))
+	b.WriteString(m.keyLine("Switch to grid view (from the branch list)", config.KeyGrid))
+	b.WriteString("  keys.json   Remap keys in ~/.config/dark-multi/keys.json\n")
 	b.WriteString("\n")
 
 	b.WriteString(sectionStyle.Render("Focused View (tmux)"))
@@ -85,8 +104,8 @@ func (m HelpModel) View() string {
 
 	b.WriteString(sectionStyle.Render("System"))
 	b.WriteString("\n")
-	b.WriteString("  ?           Help\n")
-	b.WriteString("  q           Quit\n")
+	b.WriteString(m.keyLine("Help", config.KeyHelp))
+	b.WriteString(m.keyLine("Quit", config.KeyQuit))
 	b.WriteString("\n")
 
 	b.WriteString(sectionStyle.Render("Display"))
@@ -95,6 +114,7 @@ func (m HelpModel) View() string {
 	b.WriteString("  [3/5]       Container startup progress\n")
 	b.WriteString("  3c +50 -10  Commits, lines added/removed vs main\n")
 	b.WriteString("  💬 / ⚡      Claude waiting / working\n")
+	b.WriteString("  ⏳ age: 12d  Stale branch (>14d old or >200 behind main)\n")
 	b.WriteString("\n")
 
 	b.WriteString(sectionStyle.Render("Startup Phases"))
@@ -27,9 +27,11 @@ const (
 
 // GitStatsInfo holds cached git stats for a branch.
 type GitStatsInfo struct {
-	Commits int
-	Added   int
-	Removed int
+	Commits   int
+	Added     int
+	Removed   int
+	Staleness string // e.g. "age: 12d, 340 behind"
+	Stale     bool
 }
 
 // PendingBranch tracks a branch being created.
@@ -56,6 +58,7 @@ type HomeModel struct {
 	inputMode       InputMode
 	inputText       string
 	spinner         spinner.Model
+	keys            config.Keybindings
 }
 
 // Messages
@@ -90,6 +93,7 @@ func NewHomeModel() HomeModel {
 		loading:         true,
 		spinner:         s,
 		pendingBranches: make(map[string]*PendingBranch),
+		keys:            config.LoadKeybindings(),
 	}
 }
 
@@ -109,9 +113,9 @@ func loadBranches() tea.Msg {
 
 func checkProxyStatus() tea.Msg {
 	_, running := proxy.IsRunning()
-	if !running {
+	if !running && config.AutoStartProxy {
 		// Auto-start proxy
-		proxy.Start(config.ProxyPort, true)
+		proxy.Start(config.GetProxyPort(), true, proxy.Options{})
 		_, running = proxy.IsRunning()
 	}
 	return proxyStatusMsg(running)
@@ -130,12 +134,16 @@ func loadClaudeStatus(branches []*branch.Branch) tea.Cmd {
 func loadGitStats(branches []*branch.Branch) tea.Cmd {
 	return func() tea.Msg {
 		stats := make(map[string]*GitStatsInfo)
+		batch := branch.GitStatsBatch(branches)
 		for _, b := range branches {
-			commits, added, removed := b.GitStats()
+			gs := batch[b.Name]
+			staleness, stale := b.Staleness()
 			stats[b.Name] = &GitStatsInfo{
-				Commits: commits,
-				Added:   added,
-				Removed: removed,
+				Commits:   gs.Commits,
+				Added:     gs.Added,
+				Removed:   gs.Removed,
+				Staleness: staleness,
+				Stale:     stale,
 			}
 		}
 		return gitStatsMsg(stats)
@@ -174,39 +182,22 @@ func (m HomeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.message = ""
 		m.err = nil
 
-		switch msg.String() {
-		case "q", "ctrl+c":
+		switch m.keys.Action(msg.String()) {
+		case config.KeyQuit:
 			m.quitting = true
 			return m, tea.Quit
 
-		case "up":
+		case config.KeyUp:
 			if m.cursor > 0 {
 				m.cursor--
 			}
 
-		case "down":
+		case config.KeyDown:
 			if m.cursor < len(m.branches)-1 {
 				m.cursor++
 			}
 
-		case "enter":
-			// Open Claude for selected branch (same as 'c')
-			if len(m.branches) > 0 {
-				b := m.branches[m.cursor]
-				if !b.IsRunning() {
-					m.message = fmt.Sprintf("%s is not running", b.Name)
-					return m, nil
-				}
-				containerID, _ := b.ContainerID()
-				if err := tmux.OpenClaude(b.Name, containerID); err != nil {
-					m.message = fmt.Sprintf("Error: %v", err)
-				} else {
-					m.message = fmt.Sprintf("Opened Claude for %s", b.Name)
-				}
-				return m, nil
-			}
-
-		case "t":
+		case config.KeyTerminal:
 			// Open terminal for selected branch
 			if len(m.branches) > 0 {
 				b := m.branches[m.cursor]
@@ -223,7 +214,7 @@ func (m HomeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 
-		case "s":
+		case config.KeyStart:
 			// Start selected branch
 			if len(m.branches) > 0 {
 				b := m.branches[m.cursor]
@@ -236,7 +227,7 @@ func (m HomeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 
-		case "k":
+		case config.KeyKill:
 			// Kill selected branch
 			if len(m.branches) > 0 {
 				b := m.branches[m.cursor]
@@ -249,17 +240,17 @@ func (m HomeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 
-		case "m":
+		case config.KeyMatter:
 			// Open Matter (dark-packages canvas)
 			if len(m.branches) > 0 {
 				b := m.branches[m.cursor]
-				url := fmt.Sprintf("dark-packages.%s.dlio.localhost:%d/ping", b.Name, config.ProxyPort)
+				url := fmt.Sprintf("dark-packages.%s.dlio.localhost:%d/ping", b.Name, config.GetProxyPort())
 				openInBrowser(url)
 				m.message = "Opened Matter"
 			}
 
-		case "c":
-			// Open Claude for selected branch
+		case config.KeyClaude:
+			// Open Claude for selected branch (enter or 'c' by default)
 			if len(m.branches) > 0 {
 				b := m.branches[m.cursor]
 				if !b.IsRunning() {
@@ -267,6 +258,9 @@ func (m HomeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return m, nil
 				}
 				containerID, _ := b.ContainerID()
+				if !tmux.ClaudeSessionExists(b.Name) {
+					return m, checkClaude(b.Name, containerID)
+				}
 				if err := tmux.OpenClaude(b.Name, containerID); err != nil {
 					m.message = fmt.Sprintf("Error: %v", err)
 				} else {
@@ -275,7 +269,7 @@ func (m HomeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 
-		case "e":
+		case config.KeyEditor:
 			// Open VS Code (editor) for selected branch
 			if len(m.branches) > 0 {
 				b := m.branches[m.cursor]
@@ -286,26 +280,26 @@ func (m HomeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, m.openCode(b)
 			}
 
-		case "g":
+		case config.KeyGrid:
 			// Grid view - show all Claude sessions
 			grid := NewGridModel()
 			return grid, grid.Init()
 
-		case "n":
+		case config.KeyNewBranch:
 			// New branch - enter input mode
 			m.inputMode = InputNewBranch
 			m.inputText = ""
 			m.message = ""
 			return m, nil
 
-		case "d":
+		case config.KeyDiff, config.KeyDiffExternal:
 			// Open diff view (gitk)
 			if len(m.branches) > 0 {
 				b := m.branches[m.cursor]
 				return m, m.openDiff(b)
 			}
 
-		case "x":
+		case config.KeyDelete:
 			// Delete branch - enter confirmation mode
 			if len(m.branches) > 0 {
 				m.inputMode = InputConfirmDelete
@@ -313,7 +307,7 @@ func (m HomeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 
-		case "?":
+		case config.KeyHelp:
 			// Show help
 			return NewHelpModel(), nil
 		}
@@ -347,10 +341,27 @@ func (m HomeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Periodic refresh of Claude status, git stats, and startup status
 		return m, tea.Batch(loadClaudeStatus(m.branches), loadGitStats(m.branches), loadStartupStatus(m.branches), tickCmd())
 
+	case containerEventMsg:
+		return m, loadBranches
+
 	case progressMsg:
 		m.message = msg.message
 		return m, nil
 
+	case claudeReadyMsg:
+		if msg.missing {
+			m.message = fmt.Sprintf("%s: %s", msg.name, claudeInstallStatus)
+			return m, installClaude(msg.name, msg.containerID)
+		}
+		if msg.err != nil {
+			m.message = fmt.Sprintf("Error: %v", msg.err)
+		} else if err := tmux.OpenClaude(msg.name, msg.containerID); err != nil {
+			m.message = fmt.Sprintf("Error: %v", err)
+		} else {
+			m.message = fmt.Sprintf("Opened Claude for %s", msg.name)
+		}
+		return m, nil
+
 	case createStepMsg:
 		// Clone done, now start container
 		if pending, ok := m.pendingBranches[msg.name]; ok {
@@ -466,6 +477,9 @@ func (m HomeModel) View() string {
 					stats = " " + strings.Join(parts, " ")
 					stats = modifiedStyle.Render(stats)
 				}
+				if gs.Stale {
+					stats += " " + errorStyle.Render("⏳ "+gs.Staleness)
+				}
 			}
 
 			// Claude status with activity snippet
@@ -611,11 +625,11 @@ func (m HomeModel) openCode(b *branch.Branch) tea.Cmd {
 
 func (m HomeModel) startProxy() tea.Cmd {
 	return func() tea.Msg {
-		_, err := proxy.Start(config.ProxyPort, true)
+		_, err := proxy.Start(config.GetProxyPort(), true, proxy.Options{})
 		if err != nil {
 			return operationErrMsg{err}
 		}
-		return operationDoneMsg{fmt.Sprintf("Proxy started on :%d", config.ProxyPort)}
+		return operationDoneMsg{fmt.Sprintf("Proxy started on :%d", config.GetProxyPort())}
 	}
 }
 
@@ -672,11 +686,7 @@ func (m HomeModel) handleInputMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, nil
 
 		default:
-			// Only accept valid branch name characters
-			key := msg.String()
-			if len(key) == 1 && isValidBranchChar(key[0]) {
-				m.inputText += key
-			}
+			m.inputText = appendBranchInput(m.inputText, msg)
 			return m, nil
 		}
 
@@ -706,11 +716,18 @@ func (m HomeModel) handleInputMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-func isValidBranchChar(c byte) bool {
-	return (c >= 'a' && c <= 'z') ||
-		(c >= 'A' && c <= 'Z') ||
-		(c >= '0' && c <= '9') ||
-		c == '-' || c == '_'
+// appendBranchInput appends typed or pasted text to a branch name input,
+// keeping only characters branch.ValidateName accepts.
+func appendBranchInput(text string, msg tea.KeyMsg) string {
+	if msg.Type != tea.KeyRunes {
+		return text
+	}
+	for _, r := range msg.Runes {
+		if branch.ValidateName(text+string(r)) == nil {
+			text += string(r)
+		}
+	}
+	return text
 }
 
 func (m HomeModel) createAndStartBranch(name string) tea.Cmd {
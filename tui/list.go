@@ -0,0 +1,140 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/darklang/dark-multi/branch"
+	"github.com/darklang/dark-multi/text"
+	"github.com/darklang/dark-multi/tmux"
+)
+
+// listModeMinCellWidth is the narrowest grid cell worth drawing. Below it the
+// grid falls back to one row per branch.
+const listModeMinCellWidth = 40
+
+// listNameWidth caps the name column in list mode.
+const listNameWidth = 24
+
+// inListMode reports whether to draw one row per branch instead of the grid,
+// because the user toggled it or the terminal is too narrow for the cells.
+func (m GridModel) inListMode() bool {
+	if m.listMode {
+		return true
+	}
+	return m.width > 0 && m.width/m.gridCols() < listModeMinCellWidth
+}
+
+// listRows returns how many branch rows fit above the status bar and message.
+func (m GridModel) listRows() int {
+	_, height := m.gridSize()
+	if height-3 < 1 {
+		return 1
+	}
+	return height - 3
+}
+
+// listOffset returns the first row shown, scrolling just enough to keep the
+// cursor on screen.
+func (m GridModel) listOffset() int {
+	if rows := m.listRows(); m.cursor >= rows {
+		return m.cursor - rows + 1
+	}
+	return 0
+}
+
+// renderList draws branches and pending branches one per row.
+func (m GridModel) renderList(pending []*PendingBranch) string {
+	width, _ := m.gridSize()
+	if m.width > 0 {
+		width = m.width
+	}
+
+	nameWidth := 0
+	for _, br := range m.branches {
+		nameWidth = max(nameWidth, len([]rune(br.Name)))
+	}
+	for _, pb := range pending {
+		nameWidth = max(nameWidth, len([]rune(pb.Name)))
+	}
+	nameWidth = min(nameWidth, listNameWidth)
+
+	var rows []string
+	offset := m.listOffset()
+	for i := offset; i < offset+m.listRows(); i++ {
+		if i < len(m.branches) {
+			rows = append(rows, m.renderListRow(m.branches[i], i == m.cursor, nameWidth, width))
+		} else if p := i - len(m.branches); p < len(pending) {
+			rows = append(rows, renderPendingListRow(pending[p], nameWidth, width))
+		}
+	}
+	return strings.Join(rows, "\n")
+}
+
+// renderListRow is one branch's row: status, name, Claude activity, git stats
+// and the last line of its Claude pane.
+func (m GridModel) renderListRow(br *branch.Branch, selected bool, nameWidth, width int) string {
+	marker := "  "
+	name := fmt.Sprintf("%-*s", nameWidth, text.Truncate(br.Name, nameWidth))
+	if selected {
+		marker = selectedStyle.Render("▸ ")
+		name = selectedStyle.Render(name)
+	} else {
+		name = cellHeaderStyle.Render(name)
+	}
+
+	statusIcon := stoppedStyle.Render("○")
+	if m.running[br.Name] {
+		statusIcon = runningStyle.Render("●")
+	}
+	row := marker + statusIcon + " " + name
+
+	switch m.claudeStates[br.Name] {
+	case "waiting":
+		row += " 💬"
+	case "working":
+		row += runningStyle.Render(" ⚡")
+	default:
+		row += "   "
+	}
+
+	if started, ok := m.startedAt[br.Name]; ok {
+		row += helpStyle.Render(" up " + formatDuration(time.Since(started)))
+	}
+	if gs := m.gitStats[br.Name]; gs.Commits > 0 || gs.Added > 0 || gs.Removed > 0 {
+		row += helpStyle.Render(fmt.Sprintf(" %dc +%d/-%d", gs.Commits, gs.Added, gs.Removed))
+	}
+
+	var summary string
+	switch {
+	case !m.running[br.Name]:
+		summary = cellStoppedStyle.Render("[stopped]")
+	case !tmux.BranchSessionExists(br.Name):
+		summary = stoppedStyle.Render("[ready]")
+	default:
+		summary = lastPaneLine(m.paneContent[br.Name])
+	}
+	return truncateANSI(row+"  "+summary, width)
+}
+
+// renderPendingListRow is a branch that's still being created or started.
+func renderPendingListRow(pb *PendingBranch, nameWidth, width int) string {
+	icon := lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render("◐")
+	name := cellHeaderStyle.Render(fmt.Sprintf("%-*s", nameWidth, text.Truncate(pb.Name, nameWidth)))
+	return truncateANSI("  "+icon+" "+name+"     "+helpStyle.Render(pb.Status), width)
+}
+
+// lastPaneLine returns the last line of captured pane content with anything
+// visible on it, which is usually Claude's latest output or prompt.
+func lastPaneLine(pane string) string {
+	lines := strings.Split(pane, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if lipgloss.Width(strings.TrimSpace(lines[i])) > 0 {
+			return strings.TrimSpace(lines[i])
+		}
+	}
+	return ""
+}
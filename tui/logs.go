@@ -11,6 +11,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/darklang/dark-multi/branch"
+	"github.com/darklang/dark-multi/text"
 )
 
 const (
@@ -35,17 +36,7 @@ type logRefreshMsg time.Time
 
 // NewLogViewerModel creates a log viewer for a branch.
 func NewLogViewerModel(b *branch.Branch) LogViewerModel {
-	logsDir := filepath.Join(b.Path, "rundir", "logs")
-	files := []string{}
-
-	entries, err := os.ReadDir(logsDir)
-	if err == nil {
-		for _, e := range entries {
-			if !e.IsDir() && strings.HasSuffix(e.Name(), ".log") {
-				files = append(files, e.Name())
-			}
-		}
-	}
+	files := b.LogFiles()
 
 	m := LogViewerModel{
 		branch:     b,
@@ -70,7 +61,7 @@ func (m LogViewerModel) Init() tea.Cmd {
 
 // loadLogContent reads the tail of a log file.
 func (m LogViewerModel) loadLogContent(filename string) string {
-	path := filepath.Join(m.branch.Path, "rundir", "logs", filename)
+	path := filepath.Join(m.branch.LogsDir(), filename)
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return fmt.Sprintf("Error reading %s: %v", filename, err)
@@ -126,21 +117,11 @@ func (m LogViewerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case logRefreshMsg:
 		// Auto-refresh: check for new log files and update content
 		if m.autoScroll {
-			logsDir := filepath.Join(m.branch.Path, "rundir", "logs")
-			entries, err := os.ReadDir(logsDir)
-			if err == nil {
-				var newFiles []string
-				for _, e := range entries {
-					if !e.IsDir() && strings.HasSuffix(e.Name(), ".log") {
-						newFiles = append(newFiles, e.Name())
-					}
-				}
-				// Update file list if changed
-				if len(newFiles) != len(m.logFiles) {
-					m.logFiles = newFiles
-					if len(newFiles) > 0 && m.cursor >= len(newFiles) {
-						m.cursor = 0
-					}
+			// Update file list if changed
+			if newFiles := m.branch.LogFiles(); len(newFiles) != len(m.logFiles) {
+				m.logFiles = newFiles
+				if len(newFiles) > 0 && m.cursor >= len(newFiles) {
+					m.cursor = 0
 				}
 			}
 			if len(m.logFiles) > 0 {
@@ -220,9 +201,7 @@ func (m LogViewerModel) View() string {
 		contentStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
 		for _, line := range contentLines {
 			// Truncate long lines
-			if len(line) > 70 {
-				line = line[:67] + "..."
-			}
+			line = text.Truncate(line, 70)
 			rightCol.WriteString("  " + contentStyle.Render(line) + "\n")
 		}
 
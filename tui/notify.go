@@ -0,0 +1,62 @@
+package tui
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// sendNotification shows a desktop notification, reporting false when no
+// notifier is available. It never writes to the terminal, which bubbletea owns.
+func sendNotification(title, body string) bool {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %q with title %q", body, title)
+		cmd = exec.Command("osascript", "-e", script)
+	case "linux":
+		if _, err := exec.LookPath("notify-send"); err == nil {
+			cmd = exec.Command("notify-send", "--app-name=dark-multi", title, body)
+		}
+	}
+	if cmd == nil || cmd.Start() != nil {
+		return false
+	}
+	go cmd.Wait()
+	return true
+}
+
+// waitingTransitions returns branches that moved into Claude's "waiting"
+// state since the previous poll. Branches not seen before are skipped so
+// opening the grid doesn't notify for everything already waiting.
+func waitingTransitions(prev, next map[string]string) []string {
+	var names []string
+	for name, state := range next {
+		old, known := prev[name]
+		if known && state == "waiting" && old != "waiting" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// notifyWaiting sends one notification for branches that need input, with
+// Claude's last message or tool for each so it's clear what's being asked.
+// It reports false if no notifier was available.
+func notifyWaiting(names []string, reasons map[string]string) bool {
+	if len(names) == 0 {
+		return true
+	}
+	sort.Strings(names)
+	var lines []string
+	for _, name := range names {
+		if reason := reasons[name]; reason != "" {
+			lines = append(lines, fmt.Sprintf("%s: %s", name, reason))
+		} else {
+			lines = append(lines, name+" is waiting for you")
+		}
+	}
+	return sendNotification("Claude needs input", strings.Join(lines, "\n"))
+}
@@ -1,12 +1,15 @@
 package tui
 
 import (
+	"encoding/base64"
 	"fmt"
+	"os"
 	"os/exec"
 	"runtime"
 	"strings"
 
 	"github.com/darklang/dark-multi/branch"
+	"github.com/darklang/dark-multi/config"
 )
 
 // startBranchFull starts a branch container and sets up tmux.
@@ -31,9 +34,14 @@ func stopBranchFull(b *branch.Branch) error {
 	return branch.Stop(b)
 }
 
+// stopAllFull stops every running branch.
+func stopAllFull() (int, error) {
+	return branch.StopAll()
+}
+
 // createBranchFull creates a new branch, cloning from GitHub if needed.
 func createBranchFull(name string) (*branch.Branch, error) {
-	return branch.CreateWithProgress(name, func(status string) {
+	return branch.CreateWithProgress(name, "", func(status string) {
 		if pending, ok := globalPendingBranches[name]; ok {
 			pending.Status = status
 		}
@@ -64,29 +72,32 @@ func openGitDiff(b *branch.Branch) error {
 	return fmt.Errorf("no git GUI found (tried gitk, git gui)")
 }
 
-// openVSCode opens VS Code for a branch.
+// openVSCode opens the configured editor (VS Code by default) for a branch.
 func openVSCode(b *branch.Branch) error {
 	if !b.IsRunning() {
 		return fmt.Errorf("branch %s is not running", b.Name)
 	}
 
-	// Use devcontainer CLI (preferred)
-	if _, err := exec.LookPath("devcontainer"); err == nil {
-		cmd := exec.Command("devcontainer", "open", b.Path)
-		if err := cmd.Run(); err == nil {
-			return nil
+	editor := config.GetEditor()
+
+	// Use devcontainer CLI (preferred) - it only knows how to open VS Code
+	if editor == config.DefaultEditor {
+		if _, err := exec.LookPath("devcontainer"); err == nil {
+			cmd := exec.Command("devcontainer", "open", b.Path)
+			if err := cmd.Run(); err == nil {
+				return nil
+			}
 		}
 	}
 
-	// Fallback: use code --remote attached-container
-	if _, err := exec.LookPath("code"); err == nil {
-		containerID, _ := b.ContainerID()
-		hexID := fmt.Sprintf("%x", containerID)
-		cmd := exec.Command("code", "--remote", fmt.Sprintf("attached-container+%s", hexID), "/home/dark/app")
-		return cmd.Start()
+	// VS Code and its forks (cursor, codium, ...) all attach the same way
+	if _, err := exec.LookPath(editor); err != nil {
+		return fmt.Errorf("editor %q not found (set one with 'multi set-editor')", editor)
 	}
-
-	return fmt.Errorf("neither devcontainer CLI nor VS Code found")
+	containerID, _ := b.ContainerID()
+	hexID := fmt.Sprintf("%x", containerID)
+	cmd := exec.Command(editor, "--remote", fmt.Sprintf("attached-container+%s", hexID), "/home/dark/app")
+	return cmd.Start()
 }
 
 // openInBrowser opens a URL in the default browser.
@@ -106,3 +117,43 @@ func openInBrowser(url string) {
 	}
 	cmd.Start()
 }
+
+// copyToClipboard copies s using the platform clipboard tool. Over SSH, or when
+// no tool is found, it falls back to an OSC 52 escape, which most terminals
+// forward to the local clipboard. Returns how the text was copied.
+func copyToClipboard(s string) string {
+	if os.Getenv("SSH_TTY") == "" {
+		var candidates [][]string
+		switch runtime.GOOS {
+		case "darwin":
+			candidates = [][]string{{"pbcopy"}}
+		case "linux":
+			if os.Getenv("WAYLAND_DISPLAY") != "" {
+				candidates = append(candidates, []string{"wl-copy"})
+			}
+			candidates = append(candidates,
+				[]string{"xclip", "-selection", "clipboard"},
+				[]string{"xsel", "--clipboard", "--input"})
+		case "windows":
+			candidates = [][]string{{"clip"}}
+		}
+		for _, c := range candidates {
+			if _, err := exec.LookPath(c[0]); err != nil {
+				continue
+			}
+			cmd := exec.Command(c[0], c[1:]...)
+			cmd.Stdin = strings.NewReader(s)
+			if err := cmd.Run(); err == nil {
+				return c[0]
+			}
+		}
+	}
+
+	seq := fmt.Sprintf("\x1b]52;c;%s\a", base64.StdEncoding.EncodeToString([]byte(s)))
+	if os.Getenv("TMUX") != "" {
+		// tmux needs passthrough to hand the sequence to the outer terminal
+		seq = "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+	os.Stdout.WriteString(seq)
+	return "terminal"
+}
@@ -0,0 +1,55 @@
+package tui
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/darklang/dark-multi/config"
+)
+
+// uiPrefs are grid view preferences that survive restarts.
+type uiPrefs struct {
+	Sort string `json:"sort,omitempty"`
+	List bool   `json:"list,omitempty"`
+}
+
+func uiPrefsPath() string {
+	return filepath.Join(config.ConfigDir, "ui.json")
+}
+
+// loadUIPrefs reads ui.json, returning zero prefs if it's missing or invalid.
+func loadUIPrefs() uiPrefs {
+	var prefs uiPrefs
+	data, err := os.ReadFile(uiPrefsPath())
+	if err != nil {
+		return prefs
+	}
+	json.Unmarshal(data, &prefs)
+	return prefs
+}
+
+// saveUIPrefs writes ui.json. Errors are ignored since prefs are best-effort.
+func saveUIPrefs(prefs uiPrefs) {
+	data, err := json.MarshalIndent(prefs, "", "  ")
+	if err != nil {
+		return
+	}
+	os.MkdirAll(config.ConfigDir, 0755)
+	os.WriteFile(uiPrefsPath(), append(data, '\n'), 0644)
+}
+
+// savePrefs saves the grid's current sort order and layout.
+func (m GridModel) savePrefs() {
+	saveUIPrefs(uiPrefs{Sort: m.sortMode.String(), List: m.listMode})
+}
+
+// parseGridSortMode maps a saved sort name back to a mode, defaulting to name.
+func parseGridSortMode(name string) GridSortMode {
+	for i, n := range gridSortNames {
+		if n == name {
+			return GridSortMode(i)
+		}
+	}
+	return GridSortName
+}
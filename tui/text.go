@@ -0,0 +1,46 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// ansiReset ends any color or style an escape sequence started.
+const ansiReset = "\x1b[0m"
+
+// formatDuration renders d coarsely for status lines: "45s", "12m", "1h 23m",
+// "2d 3h".
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh %dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("%dd %dh", int(d.Hours()/24), int(d.Hours())%24)
+}
+
+// truncateANSI shortens s to width visible cells, ending with "…" when cut.
+// Escape sequences (colors, OSC hyperlinks and titles, charset switches) are
+// copied through without counting toward the width and are never split; if
+// a cut line had any, a reset is appended so a cut-off color doesn't bleed
+// into the rest of the cell.
+func truncateANSI(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if lipgloss.Width(s) <= width {
+		return s
+	}
+	out := ansi.Truncate(s, width, "…")
+	if strings.ContainsRune(s, '\x1b') && !strings.HasSuffix(out, ansiReset) {
+		out += ansiReset
+	}
+	return out
+}
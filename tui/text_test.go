@@ -0,0 +1,51 @@
+package tui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTruncateANSI(t *testing.T) {
+	red := "\x1b[31m"
+	tests := []struct {
+		name  string
+		s     string
+		width int
+		want  string
+	}{
+		{"fits", "hello", 5, "hello"},
+		{"plain cut", "hello world", 6, "hello…"},
+		{"colored fits", red + "hi" + ansiReset, 2, red + "hi" + ansiReset},
+		{"escape not counted or split", red + "hello world" + ansiReset, 6, red + "hello…" + ansiReset},
+		{"wide runes", "日本語テキスト", 5, "日本…"},
+		{"osc hyperlink", "\x1b]8;;http://x\x07link text\x1b]8;;\x07", 6, "\x1b]8;;http://x\x07link …\x1b]8;;\x07" + ansiReset},
+		{"osc with st", "\x1b]0;title\x1b\\hello world", 6, "\x1b]0;title\x1b\\hello…" + ansiReset},
+		{"two-byte escape", "\x1b(Bhello world", 6, "\x1b(Bhello…" + ansiReset},
+		{"zero width", "hello", 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncateANSI(tt.s, tt.width); got != tt.want {
+				t.Errorf("truncateANSI(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    string
+		want string
+	}{
+		{"45s", "45s"},
+		{"12m30s", "12m"},
+		{"1h23m", "1h 23m"},
+		{"51h", "2d 3h"},
+	}
+	for _, tt := range tests {
+		d, _ := time.ParseDuration(tt.d)
+		if got := formatDuration(d); got != tt.want {
+			t.Errorf("formatDuration(%s) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
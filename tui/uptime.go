@@ -0,0 +1,66 @@
+package tui
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/darklang/dark-multi/branch"
+	"github.com/darklang/dark-multi/config"
+	"github.com/darklang/dark-multi/container"
+)
+
+// uptimeCheckInterval is how often running containers are checked against
+// config.MaxUptime.
+const uptimeCheckInterval = time.Minute
+
+// uptimeStoppedMsg names branches stopped for running longer than MaxUptime.
+type uptimeStoppedMsg []string
+
+// watchUptime enforces config.MaxUptime for as long as the TUI runs,
+// whichever view is showing.
+func watchUptime(ctx context.Context, p *tea.Program) {
+	if config.MaxUptime <= 0 {
+		return
+	}
+	ticker := time.NewTicker(uptimeCheckInterval)
+	defer ticker.Stop()
+	for {
+		if names := stopOverMaxUptime(); len(names) > 0 {
+			p.Send(uptimeStoppedMsg(names))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// stopOverMaxUptime stops branches whose containers have been up longer than
+// config.MaxUptime and returns their names.
+func stopOverMaxUptime() []string {
+	running := branch.RunningNames()
+	var stopped []string
+	for _, b := range branch.GetManagedBranches() {
+		if !running[b.Name] {
+			continue
+		}
+		id, err := b.ContainerID()
+		if err != nil || id == "" {
+			continue
+		}
+		started, err := container.StartedAt(id)
+		if err != nil || time.Since(started) < config.MaxUptime {
+			continue
+		}
+		logger.Infof("Stopping %s: up %s, longer than DARK_MULTI_MAX_UPTIME", b.Name, formatDuration(time.Since(started)))
+		if err := branch.Stop(b); err != nil {
+			logger.Warnf("Stopping %s: %v", b.Name, err)
+			continue
+		}
+		stopped = append(stopped, b.Name)
+	}
+	return stopped
+}